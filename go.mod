@@ -6,6 +6,7 @@ require (
 	github.com/prometheus/client_golang v1.19.1
 	github.com/prometheus/client_model v0.6.1
 	github.com/rumblefrog/go-a2s v1.0.2
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
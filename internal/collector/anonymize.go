@@ -0,0 +1,73 @@
+package collector
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/rumblefrog/go-a2s"
+)
+
+// PlayerNameMode selects how player names appear in the metrics and every other output.
+type PlayerNameMode string
+
+const (
+	// PlayerNameRaw keeps player names as reported by the server.
+	PlayerNameRaw PlayerNameMode = "raw"
+	// PlayerNameHashed replaces player names with a keyed hash, which is stable but cannot be reversed without the
+	// secret.
+	PlayerNameHashed PlayerNameMode = "hashed"
+	// PlayerNameOmitted removes player names. Per-player labeled metrics are not exported, since they are identified by
+	// name.
+	PlayerNameOmitted PlayerNameMode = "omitted"
+)
+
+// hashedNameLength is the number of hex characters kept from the hash of a player name.
+const hashedNameLength = 16
+
+// ParsePlayerNameMode validates a PlayerNameMode. An empty string is PlayerNameRaw.
+func ParsePlayerNameMode(s string) (PlayerNameMode, error) {
+	switch mode := PlayerNameMode(s); mode {
+	case "":
+		return PlayerNameRaw, nil
+	case PlayerNameRaw, PlayerNameHashed, PlayerNameOmitted:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown player name mode %q", s)
+	}
+}
+
+// anonymizePlayers replaces the player names in place according to the player name mode. It is applied to the query
+// results before they are used for anything else, so that raw names never leave the collector.
+func (c *Collector) anonymizePlayers(playerInfo *a2s.PlayerInfo) {
+	if playerInfo == nil {
+		return
+	}
+
+	switch c.playerNameMode {
+	case PlayerNameHashed:
+		for _, player := range playerInfo.Players {
+			player.Name = hashPlayerName(c.playerNameSecret, player.Name)
+		}
+	case PlayerNameOmitted:
+		for _, player := range playerInfo.Players {
+			player.Name = ""
+		}
+	}
+}
+
+// playerNameFingerprint identifies how player names are anonymized, so that state keyed by the names of one mode or
+// secret is not mixed with names of another. The secret itself is not revealed.
+func (c *Collector) playerNameFingerprint() string {
+	if c.playerNameMode != PlayerNameHashed {
+		return ""
+	}
+	return hashPlayerName(c.playerNameSecret, "a2s-exporter state")
+}
+
+func hashPlayerName(secret []byte, name string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(name))
+	return hex.EncodeToString(mac.Sum(nil))[:hashedNameLength]
+}
@@ -0,0 +1,105 @@
+package collector_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rumblefrog/go-a2s"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+	"github.com/armsnyder/a2s-exporter/internal/testserver"
+)
+
+func TestCollector_PlayerNameHashed(t *testing.T) {
+	addr := testServe(t, &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo"},
+		PlayerInfo: &a2s.PlayerInfo{Count: 2, Players: []*a2s.Player{
+			{Name: "jon", Duration: 30},
+			{Name: "alice", Duration: 60},
+		}},
+	})
+
+	hashedNames := func(secret string) []string {
+		c := collector.New("", addr, collector.Options{
+			PlayerNameMode:   collector.PlayerNameHashed,
+			PlayerNameSecret: []byte(secret),
+		})
+		defer c.Close()
+
+		registry := prometheus.NewPedanticRegistry()
+		registry.MustRegister(c)
+		metrics := testGather(t, registry)
+
+		var names []string
+		for _, family := range metrics {
+			if family.GetName() != "player_info" {
+				continue
+			}
+			for _, metric := range family.GetMetric() {
+				for _, label := range metric.GetLabel() {
+					if label.GetName() == "player_name" {
+						names = append(names, label.GetValue())
+					}
+				}
+			}
+		}
+
+		// The names are also hashed in the status used by the web pages.
+		for _, player := range c.Status().PlayerInfo.Players {
+			if player.Name == "jon" || player.Name == "alice" {
+				t.Errorf("raw player name %q in status", player.Name)
+			}
+		}
+
+		return names
+	}
+
+	names := hashedNames("secret")
+	if len(names) != 2 {
+		t.Fatalf("expected 2 player names but got %v", names)
+	}
+	for _, name := range names {
+		if !regexp.MustCompile(`^[0-9a-f]{16}$`).MatchString(name) {
+			t.Errorf("expected a hashed player name but got %q", name)
+		}
+	}
+
+	// Hashes are stable for the same secret and differ for another secret.
+	if again := hashedNames("secret"); strings.Join(again, ",") != strings.Join(names, ",") {
+		t.Errorf("expected stable hashes %v but got %v", names, again)
+	}
+	if other := hashedNames("other"); strings.Join(other, ",") == strings.Join(names, ",") {
+		t.Errorf("expected different hashes for a different secret but got %v", other)
+	}
+}
+
+func TestCollector_PlayerNameOmitted(t *testing.T) {
+	addr := testServe(t, &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo"},
+		PlayerInfo: &a2s.PlayerInfo{Count: 1, Players: []*a2s.Player{
+			{Name: "jon", Duration: 30},
+		}},
+	})
+
+	c := collector.New("", addr, collector.Options{PlayerNameMode: collector.PlayerNameOmitted, PlayerMode: collector.PlayerModeBoth})
+	defer c.Close()
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(c)
+	metrics := testGather(t, registry)
+
+	testAssertValue(t, metrics, "player_count", 1)
+	testAssertValue(t, metrics, "player_duration_max_seconds", 30)
+
+	for _, family := range metrics {
+		if family.GetName() == "player_info" {
+			t.Error("expected no per-player metrics")
+		}
+	}
+
+	if name := c.Status().PlayerInfo.Players[0].Name; name != "" {
+		t.Errorf("expected player name to be omitted from status but got %q", name)
+	}
+}
@@ -0,0 +1,72 @@
+package collector
+
+import (
+	"github.com/rumblefrog/go-a2s"
+)
+
+// Classes of player list entries.
+const (
+	playerClassReal       = "real"
+	playerClassConnecting = "connecting"
+	playerClassBot        = "bot"
+)
+
+// classifyPlayer guesses what a player list entry represents. Many servers list players who are still connecting as
+// placeholders with an empty name or no connection time, and bots by name.
+func (c *Collector) classifyPlayer(player *a2s.Player) string {
+	switch {
+	case player.Name == "" || player.Duration <= 0:
+		return playerClassConnecting
+	case c.isBotName(player.Name):
+		return playerClassBot
+	default:
+		return playerClassReal
+	}
+}
+
+func (c *Collector) isBotName(name string) bool {
+	for _, pattern := range c.botNamePatterns {
+		if pattern.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// countPlayerClasses counts the player list entries of each class. It must be called before excluded players are
+// removed, since the server info counts them, and before the player names are anonymized, since the classes are told
+// apart by name.
+func (c *Collector) countPlayerClasses(playerInfo *a2s.PlayerInfo) map[string]int {
+	if playerInfo == nil {
+		return nil
+	}
+
+	counts := map[string]int{
+		playerClassReal:       0,
+		playerClassConnecting: 0,
+		playerClassBot:        0,
+	}
+
+	for _, player := range playerInfo.Players {
+		counts[c.classifyPlayer(player)]++
+	}
+
+	return counts
+}
+
+// collectPlayerClasses exports the number of player list entries of each class, and how far the player list is from
+// the counts reported in the server info.
+func (c *Collector) collectPlayerClasses(serverInfo *a2s.ServerInfo, playerInfo *a2s.PlayerInfo, counts map[string]int, add adder) {
+	if serverInfo == nil || playerInfo == nil {
+		return
+	}
+
+	var entries int
+	for class, count := range counts {
+		add("player_list_entries", float64(count), class)
+		entries += count
+	}
+
+	add("player_list_discrepancy", float64(int(serverInfo.Players)-entries), "players")
+	add("player_list_discrepancy", float64(int(serverInfo.Bots)-counts[playerClassBot]), "bots")
+}
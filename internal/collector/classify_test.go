@@ -0,0 +1,81 @@
+package collector_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rumblefrog/go-a2s"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+	"github.com/armsnyder/a2s-exporter/internal/testserver"
+)
+
+func TestCollector_PlayerClasses(t *testing.T) {
+	addr := testServe(t, &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo", Players: 6, Bots: 2},
+		PlayerInfo: &a2s.PlayerInfo{Count: 5, Players: []*a2s.Player{
+			{Name: "jon", Duration: 30},
+			{Name: "alice", Duration: 60},
+			{Name: "", Duration: 12},
+			{Name: "bob", Duration: 0},
+			{Name: "BOT Kyle", Duration: 600},
+		}},
+	})
+
+	// Entries are classified by their names as reported, whichever way the names are exported.
+	for _, mode := range []collector.PlayerNameMode{collector.PlayerNameRaw, collector.PlayerNameHashed, collector.PlayerNameOmitted} {
+		t.Run(string(mode), func(t *testing.T) {
+			registry := prometheus.NewPedanticRegistry()
+			registry.MustRegister(collector.New("", addr, collector.Options{
+				PlayerNameMode:   mode,
+				PlayerNameSecret: []byte("secret"),
+				BotNamePatterns:  []*regexp.Regexp{regexp.MustCompile(`^BOT `)},
+			}))
+			metrics := testGather(t, registry)
+
+			testAssertGauge(t, metrics, "player_list_entries",
+				expectGauge{value: 2, labels: map[string]string{"class": "real"}},
+				expectGauge{value: 2, labels: map[string]string{"class": "connecting"}},
+				expectGauge{value: 1, labels: map[string]string{"class": "bot"}},
+			)
+			testAssertGauge(t, metrics, "player_list_discrepancy",
+				expectGauge{value: 1, labels: map[string]string{"kind": "players"}},
+				expectGauge{value: 1, labels: map[string]string{"kind": "bots"}},
+			)
+		})
+	}
+}
+
+func TestCollector_PlayerClasses_Excluded(t *testing.T) {
+	addr := testServe(t, &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo", Players: 3, Bots: 1},
+		PlayerInfo: &a2s.PlayerInfo{Count: 3, Players: []*a2s.Player{
+			{Name: "jon", Duration: 30},
+			{Name: "alice", Duration: 60},
+			{Name: "BOT Bob", Duration: 600},
+		}},
+	})
+
+	// Excluded players are left out of the per-player metrics, but still counted, so there is no discrepancy.
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector.New("", addr, collector.Options{
+		ExcludePlayerNames: []*regexp.Regexp{regexp.MustCompile(`^BOT `)},
+		BotNamePatterns:    []*regexp.Regexp{regexp.MustCompile(`^BOT `)},
+	}))
+	metrics := testGather(t, registry)
+
+	testAssertGauge(t, metrics, "player_list_entries",
+		expectGauge{value: 2, labels: map[string]string{"class": "real"}},
+		expectGauge{value: 0, labels: map[string]string{"class": "connecting"}},
+		expectGauge{value: 1, labels: map[string]string{"class": "bot"}},
+	)
+	testAssertGauge(t, metrics, "player_list_discrepancy",
+		expectGauge{value: 0, labels: map[string]string{"kind": "players"}},
+		expectGauge{value: 0, labels: map[string]string{"kind": "bots"}},
+	)
+	testAssertGauge(t, metrics, "player_duration",
+		expectGauge{value: 30, labels: map[string]string{"player_name": "jon"}},
+		expectGauge{value: 60, labels: map[string]string{"player_name": "alice"}},
+	)
+}
@@ -2,40 +2,218 @@ package collector
 
 import (
 	"fmt"
+	"os"
 	"reflect"
+	"regexp"
+	"sync"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rumblefrog/go-a2s"
+
+	"github.com/armsnyder/a2s-exporter/internal/events"
 )
 
 type Collector struct {
 	addr                 string
 	clientOptions        []func(*a2s.Client) error
 	excludePlayerMetrics bool
-	client               *a2s.Client
+	stateless            bool
+	playerMode           PlayerMode
+	infoMode             InfoMode
+	serverLabels         []string
+	infoLabels           []string
+	maxPlayerSeries      int
+	playerOrder          PlayerOrder
+	playerNameMode       PlayerNameMode
+	playerNameSecret     []byte
+	duplicateMode        DuplicateMode
+	normalizePlayerNames bool
+	playerNameMaxLength  int
+	excludePlayerNames   []*regexp.Regexp
+	botNamePatterns      []*regexp.Regexp
+	queryRules           bool
+	ruleMetrics          []RuleMetric
+	events               events.Sink
 	descs                map[string]*prometheus.Desc
+	keptLabels           map[string][]int
+	valueTypes           map[string]prometheus.ValueType
+
+	// statusMu guards status without waiting for a query in progress. status is written while holding both statusMu
+	// and mu, so it may be read while holding either.
+	statusMu sync.Mutex
+	status   Status
+
+	// mu serializes queries, since the A2S client is not safe for concurrent use, and guards the fields below.
+	mu       sync.Mutex
+	client   *a2s.Client
+	sessions sessionTracker
+	stats    statsTracker
+	rounds   roundTracker
+	maps     mapTracker
+	restarts restartTracker
+	// lastServerInfo is from the most recent successful server info query.
+	lastServerInfo *a2s.ServerInfo
+	// restoredStatus is the status before the exporter restarted, which stands in for the previous status on the first
+	// query after RestoreState.
+	restoredStatus Status
+}
+
+// Status describes the outcome of the most recent query of the A2S server.
+type Status struct {
+	// Time is when the query completed. It is zero if the server has not been queried yet.
+	Time       time.Time
+	ServerName string
+	ServerUp   bool
+	PlayerUp   bool
+	// Err is the first error encountered during the query, if any.
+	Err error
+
+	// The latest query results. These may be nil, and must not be modified.
+	ServerInfo *a2s.ServerInfo
+	PlayerInfo *a2s.PlayerInfo
+	Rules      *a2s.RulesInfo
+}
+
+// Options configures a Collector.
+type Options struct {
+	// ExcludePlayerMetrics skips the player query and all player_* metrics.
+	ExcludePlayerMetrics bool
+	// Stateless leaves out the metrics which are derived by comparing queries, such as player_joins_total, for a
+	// Collector which is only queried once, such as for a probe.
+	Stateless bool
+	// PlayerMode selects how player metrics are exported. The default is PlayerModeLabeled.
+	PlayerMode PlayerMode
+	// IdentityLabels selects the labels which identify the server on every metric. The default is IdentityName. If
+	// IdentityName is not selected, server_name is only exported as an info label.
+	IdentityLabels []IdentityLabel
+	// Alias is the value of the server_alias label with IdentityAlias.
+	Alias string
+	// Labels are added to every metric. They must be valid according to ValidateLabels.
+	Labels map[string]string
+	// IncludeMetrics, if set, limits the metrics to those whose name (without the namespace) matches any of the patterns.
+	IncludeMetrics []*regexp.Regexp
+	// ExcludeMetrics leaves out the metrics whose name (without the namespace) matches any of the patterns.
+	ExcludeMetrics []*regexp.Regexp
+	// DropLabels leaves out labels of metrics, by metric name (without the namespace). Dropping a label which tells
+	// series apart, such as player_name, makes the series collide and fails the scrape.
+	DropLabels map[string][]string
+	// InfoMode selects how the non-numerical server info is exported. The default is InfoModeCombined.
+	InfoMode InfoMode
+	// MaxPlayerSeries limits the number of players exported with per-player labels. Zero means no limit.
+	MaxPlayerSeries int
+	// PlayerOrder selects which players are kept when MaxPlayerSeries is exceeded. The default is PlayerOrderScore.
+	PlayerOrder PlayerOrder
+	// PlayerNameMode selects how player names appear in all outputs. The default is PlayerNameRaw.
+	PlayerNameMode PlayerNameMode
+	// PlayerNameSecret is the key used to hash player names with PlayerNameHashed.
+	PlayerNameSecret []byte
+	// DuplicateMode selects how players with the same name are exported. The default is DuplicateDrop.
+	DuplicateMode DuplicateMode
+	// NormalizePlayerNames strips markup, control characters and invalid UTF-8 from player names.
+	NormalizePlayerNames bool
+	// PlayerNameMaxLength truncates normalized player names to this many characters. Zero means no limit.
+	PlayerNameMaxLength int
+	// ExcludePlayerNames removes the players whose (normalized) name matches any of the patterns from all outputs. They
+	// are still counted by player_list_entries, so that the counts match the server info.
+	ExcludePlayerNames []*regexp.Regexp
+	// BotNamePatterns classify the players whose name matches any of the patterns as bots.
+	BotNamePatterns []*regexp.Regexp
+	// QueryRules additionally queries the server rules by default. The rules are counted by the server_rules metric,
+	// and are available in the Status.
+	QueryRules bool
+	// RuleMetrics selects rules which are exported as the server_rule metric when the rules are queried.
+	RuleMetrics []RuleMetric
+	// ClientOptions are passed to the A2S client.
+	ClientOptions []func(*a2s.Client) error
+	// Events receives the changes observed between queries. Optional.
+	Events events.Sink
+}
+
+// statefulMetrics are derived by comparing queries, so they are meaningless for a Collector which is only queried once.
+var statefulMetrics = []string{
+	"player_score_total",
+	"player_peak",
+	"player_unique_names_today",
+	"player_seconds_total",
+	"server_restarts_total",
+	"server_version_changes_total",
+	"server_start_timestamp_seconds",
+	"map_changes_total",
+	"current_map_start_timestamp_seconds",
+	"map_seconds_total",
+	"round_changes_total",
+	"player_joins_total",
+	"player_leaves_total",
+	"player_sessions_active",
+	"player_session_duration_seconds",
 }
 
 type adder func(name string, value float64, labelValues ...string)
 
-func New(namespace, addr string, excludePlayerMetrics bool, clientOptions ...func(*a2s.Client) error) *Collector {
+type histogramAdder func(name string, count uint64, sum float64, buckets map[float64]uint64, labelValues ...string)
+
+func New(namespace, addr string, opts Options) *Collector {
+	if opts.PlayerNameMode == "" {
+		opts.PlayerNameMode = PlayerNameRaw
+	}
+
 	descs := make(map[string]*prometheus.Desc)
+	valueTypes := make(map[string]prometheus.ValueType)
+
+	constLabels, serverLabels := identity(opts.IdentityLabels, addr, opts.Alias)
+	for name, value := range opts.Labels {
+		constLabels[name] = value
+	}
+
+	keptLabels := make(map[string][]int)
 
+	// Metrics which are filtered out are not described, and are skipped when collecting.
 	fullDesc := func(name, help string, labels ...string) {
-		descs[name] = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, labels, nil)
+		if !includeMetric(name, opts.IncludeMetrics, opts.ExcludeMetrics) {
+			return
+		}
+		labels, indices := dropLabels(labels, opts.DropLabels[name])
+		if indices != nil {
+			keptLabels[name] = indices
+		}
+		descs[name] = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, labels, constLabels)
+	}
+	// identityDesc describes a metric of a server which answered, labeled with the identity labels from the server
+	// info followed by the given labels.
+	identityDesc := func(name, help string, labels ...string) {
+		fullDesc(name, help, append(append([]string{}, serverLabels...), labels...)...)
 	}
 	basicDesc := func(name string, help string) {
-		fullDesc(name, help, "server_name")
+		identityDesc(name, help)
+	}
+	counterDesc := func(name string, help string) {
+		basicDesc(name, help)
+		valueTypes[name] = prometheus.CounterValue
 	}
 	playerDesc := func(name string, help string) {
-		fullDesc(name, help, "server_name", "player_name", "player_index")
+		identityDesc(name, help, "player_name", "player_index")
 	}
 
-	fullDesc("server_info", "Non-numerical server info, including server_steam_id and version. The value is 1, and info is in the labels.",
-		"server_name", "map", "folder", "game", "server_type", "server_os", "version", "server_id", "keywords", "server_game_id", "server_steam_id", "the_ship_mode", "source_tv_name")
+	var infoLabels []string
+	for _, label := range serverInfoLabels {
+		if !containsString(serverLabels, label) {
+			infoLabels = append(infoLabels, label)
+		}
+	}
+	identityDesc("server_info", "Non-numerical server info, including server_steam_id and version. The value is 1, and info is in the labels.", infoLabels...)
+	if !containsString(serverLabels, "server_name") {
+		identityDesc("server_name_info", "Server name, when it is not an identity label. The value is 1, and the name is in the label.", "server_name")
+	}
+
+	identityDesc("server_version_info", "Server version. The value is 1, and the version is in the label.", "version")
+	identityDesc("server_map_info", "Current map. The value is 1, and the map is in the label.", "map")
+	identityDesc("server_game_info", "Game the server is running. The value is 1, and the info is in the labels.", "game", "folder", "server_id", "server_game_id", "server_type", "server_os")
+	identityDesc("server_keywords_info", "Server keywords (tags). The value is 1, and the keywords are in the label.", "keywords")
 
 	fullDesc("server_up", "Was the last server info query successful.")
 	fullDesc("player_up", "Was the last player info query successful.")
+	fullDesc("rules_up", "Was the last rules query successful. Only exported when the rules are queried.")
 
 	basicDesc("server_protocol", "Protocol version used by the server.")
 	basicDesc("server_players", "Number of players on the server.")
@@ -45,6 +223,8 @@ func New(namespace, addr string, excludePlayerMetrics bool, clientOptions ...fun
 	basicDesc("server_vac", "Specifies whether the server uses VAC (0 for unsecured, 1 for secured).")
 	basicDesc("server_port", "The server's game port number.")
 	basicDesc("server_source_tv_port", "Spectator port number for SourceTV.")
+	basicDesc("server_rules", "Number of rules (server variables) reported by the server. Only exported when the rules are queried.")
+	identityDesc("server_rule", "Value of a server rule selected by a module. Rules whose value is not a number or a boolean are left out.", "rule")
 	basicDesc("server_the_ship_witnesses", "The number of witnesses necessary to have a player arrested in a The Ship server.")
 	basicDesc("server_the_ship_duration", "Time (in seconds) before a player is arrested while being witnessed in a The Ship server.")
 
@@ -52,17 +232,110 @@ func New(namespace, addr string, excludePlayerMetrics bool, clientOptions ...fun
 	playerDesc("player_info", "Non-numerical player info, including player_name and player_index. The value is 1, and the info is in the labels.")
 	playerDesc("player_duration", "Time (in seconds) player has been connected to the server.")
 	playerDesc("player_score", `Player's score (usually "frags" or "kills").`)
+	playerDesc("player_score_total", "Player's score accumulated across rounds, for use with rate(). A new round is detected by a map change, most scores resetting to zero, or all players reconnecting.")
+	valueTypes["player_score_total"] = prometheus.CounterValue
 	playerDesc("player_the_ship_deaths", "Player's deaths in a The Ship server.")
 	playerDesc("player_the_ship_money", "Player's money in a The Ship server.")
+	playerDesc("player_instances", "Number of players sharing the player name, when duplicate names are aggregated.")
+	basicDesc("player_duplicate_names", "Number of players whose name was already taken by another player on the server.")
+	identityDesc("player_list_entries", "Number of entries in the player list by class: real, connecting (empty name or no connection time), or bot (name matches a bot pattern).", "class")
+	identityDesc("player_list_discrepancy", `Difference between the count reported in the server info and the player list: "players" compares the player count with the number of entries, and "bots" compares the bot count with the entries classified as bots.`, "kind")
+	identityDesc("player_peak", "Highest number of players on the server within the window, as reported by the server info.", "window")
+	// Names cannot be counted when they are omitted, and zero would read as nobody having played.
+	if opts.PlayerNameMode != PlayerNameOmitted {
+		basicDesc("player_unique_names_today", "Number of distinct player names seen since midnight (in the exporter's time zone). Not exported when player names are omitted.")
+	}
+	counterDesc("player_seconds_total", "Total time (in seconds) spent on the server by all players, summed across players.")
+	basicDesc("players_truncated", "Number of players left out of the per-player metrics because of the limit on player series.")
+
+	counterDesc("server_restarts_total", "Number of server restarts, detected by a version or Steam ID change, the server answering again after being down with only players who connected since (or, if empty, after being down for 2 minutes and 2 queries), or all players reconnecting without a map change.")
+	counterDesc("server_version_changes_total", "Number of times the server version changed.")
+	basicDesc("server_start_timestamp_seconds", "Estimated start of the server as a Unix timestamp, for uptime graphs. Until a restart is seen, it is when the longest connected player connected.")
+	counterDesc("map_changes_total", "Number of times the server changed map.")
+	basicDesc("current_map_start_timestamp_seconds", "When the current map was first seen, as a Unix timestamp. For the map running when the exporter started, this is when the exporter first queried the server.")
+	identityDesc("map_seconds_total", "Time (in seconds) the server spent on each map while the exporter was running.", "map")
+	valueTypes["map_seconds_total"] = prometheus.CounterValue
+	counterDesc("round_changes_total", "Number of new rounds detected by a map change, most scores resetting to zero, or all players reconnecting.")
+
+	counterDesc("player_joins_total", "Number of players who joined the server, detected by comparing player lists between queries.")
+	counterDesc("player_leaves_total", "Number of players who left the server, detected by comparing player lists between queries.")
+	basicDesc("player_sessions_active", "Number of player sessions currently being tracked.")
+	basicDesc("player_session_duration_seconds", "Histogram of the duration of completed player sessions.")
+
+	basicDesc("player_duration_distribution_seconds", "Histogram of the time (in seconds) each player in the player list has been connected.")
+	basicDesc("player_duration_min_seconds", "Shortest time (in seconds) a player in the player list has been connected.")
+	basicDesc("player_duration_max_seconds", "Longest time (in seconds) a player in the player list has been connected.")
+	basicDesc("player_duration_median_seconds", "Median time (in seconds) the players in the player list have been connected.")
+	basicDesc("player_score_distribution", "Histogram of the score of each player in the player list.")
+	basicDesc("player_score_min", "Lowest score of a player in the player list.")
+	basicDesc("player_score_max", "Highest score of a player in the player list.")
+	basicDesc("player_score_median", "Median score of the players in the player list.")
+
+	if opts.Stateless {
+		for _, name := range statefulMetrics {
+			delete(descs, name)
+		}
+	}
 
 	return &Collector{
 		addr:                 addr,
-		clientOptions:        clientOptions,
-		excludePlayerMetrics: excludePlayerMetrics,
+		clientOptions:        opts.ClientOptions,
+		excludePlayerMetrics: opts.ExcludePlayerMetrics,
+		stateless:            opts.Stateless,
+		playerMode:           opts.PlayerMode,
+		infoMode:             opts.InfoMode,
+		serverLabels:         serverLabels,
+		infoLabels:           infoLabels,
+		maxPlayerSeries:      opts.MaxPlayerSeries,
+		playerOrder:          opts.PlayerOrder,
+		playerNameMode:       opts.PlayerNameMode,
+		playerNameSecret:     opts.PlayerNameSecret,
+		duplicateMode:        opts.DuplicateMode,
+		normalizePlayerNames: opts.NormalizePlayerNames,
+		playerNameMaxLength:  opts.PlayerNameMaxLength,
+		excludePlayerNames:   opts.ExcludePlayerNames,
+		botNamePatterns:      opts.BotNamePatterns,
+		queryRules:           opts.QueryRules,
+		ruleMetrics:          opts.RuleMetrics,
+		events:               opts.Events,
 		descs:                descs,
+		keptLabels:           keptLabels,
+		valueTypes:           valueTypes,
+		sessions:             newSessionTracker(),
 	}
 }
 
+// Addr returns the address of the A2S query server.
+func (c *Collector) Addr() string {
+	return c.addr
+}
+
+// Status returns the outcome of the most recent query. It does not wait for a query in progress.
+func (c *Collector) Status() Status {
+	c.statusMu.Lock()
+	defer c.statusMu.Unlock()
+	return c.status
+}
+
+// Refresh queries the A2S server without collecting metrics, updating the Status.
+func (c *Collector) Refresh() {
+	c.query(c.DefaultScope())
+}
+
+// Close releases the UDP client, if one was created. The Collector may still be used afterwards.
+func (c *Collector) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.client == nil {
+		return nil
+	}
+
+	err := c.client.Close()
+	c.client = nil
+	return err
+}
+
 func (c *Collector) Describe(descs chan<- *prometheus.Desc) {
 	for _, desc := range c.descs {
 		descs <- desc
@@ -70,7 +343,12 @@ func (c *Collector) Describe(descs chan<- *prometheus.Desc) {
 }
 
 func (c *Collector) Collect(metrics chan<- prometheus.Metric) {
-	serverInfo, playerInfo := c.queryInfo(c.excludePlayerMetrics)
+	c.collect(c.DefaultScope(), metrics)
+}
+
+func (c *Collector) collect(scope Scope, metrics chan<- prometheus.Metric) {
+	result := c.query(scope)
+	serverInfo, playerInfo, rules := result.serverInfo, result.playerInfo, result.rules
 
 	truthyFloat := func(v interface{}) float64 {
 		if reflect.ValueOf(v).IsNil() {
@@ -80,34 +358,162 @@ func (c *Collector) Collect(metrics chan<- prometheus.Metric) {
 	}
 
 	add := func(name string, value float64, labelValues ...string) {
-		metrics <- prometheus.MustNewConstMetric(c.descs[name], prometheus.GaugeValue, value, labelValues...)
+		desc, ok := c.descs[name]
+		if !ok {
+			return
+		}
+		valueType, ok := c.valueTypes[name]
+		if !ok {
+			valueType = prometheus.GaugeValue
+		}
+		metrics <- prometheus.MustNewConstMetric(desc, valueType, value, c.keptLabelValues(name, labelValues)...)
 	}
 
 	add("server_up", truthyFloat(serverInfo))
 
-	if !c.excludePlayerMetrics {
+	if scope.Players {
 		add("player_up", truthyFloat(playerInfo))
 	}
 
+	if scope.Rules && serverInfo != nil {
+		add("rules_up", truthyFloat(rules))
+	}
+
+	var identityValues []string
+	if serverInfo != nil {
+		identityValues = c.identityValues(serverInfo)
+	}
+
 	addPreLabelled := func(name string, value float64, labelValues ...string) {
-		labelValues2 := []string{serverInfo.Name}
+		labelValues2 := append([]string{}, identityValues...)
 		labelValues2 = append(labelValues2, labelValues...)
 		add(name, value, labelValues2...)
 	}
 
+	addHistogramPreLabelled := func(name string, count uint64, sum float64, buckets map[float64]uint64, labelValues ...string) {
+		labelValues2 := append([]string{}, identityValues...)
+		labelValues2 = append(labelValues2, labelValues...)
+		desc, ok := c.descs[name]
+		if !ok {
+			return
+		}
+		metrics <- prometheus.MustNewConstHistogram(desc, count, sum, buckets, c.keptLabelValues(name, labelValues2)...)
+	}
+
 	c.collectServerInfo(serverInfo, addPreLabelled)
-	c.collectPlayerInfo(playerInfo, addPreLabelled)
+	c.collectPlayerInfo(result, addPreLabelled)
+	c.collectRules(rules, addPreLabelled)
+
+	if scope.Players {
+		c.collectPlayerClasses(serverInfo, playerInfo, result.playerClasses, addPreLabelled)
+	}
+
+	if c.playerMode.aggregated() {
+		c.collectPlayerDistribution(playerInfo, addPreLabelled, addHistogramPreLabelled)
+	}
+
+	if serverInfo != nil && !c.stateless {
+		c.mu.Lock()
+		c.collectRestarts(addPreLabelled)
+		c.collectMaps(addPreLabelled)
+		addPreLabelled("round_changes_total", c.rounds.changes)
+		if scope.Players {
+			c.collectStats(addPreLabelled)
+			c.collectSessions(addPreLabelled, addHistogramPreLabelled)
+		}
+		c.mu.Unlock()
+	}
 }
 
-// queryInfo queries the A2S server over UDP. Failure will result in one or both of the return values being nil.
-func (c *Collector) queryInfo(excludePlayerMetrics bool) (serverInfo *a2s.ServerInfo, playerInfo *a2s.PlayerInfo) {
-	var err error
+// queryResult is the outcome of a query which is collected into metrics.
+type queryResult struct {
+	serverInfo *a2s.ServerInfo
+	playerInfo *a2s.PlayerInfo
+	rules      *a2s.RulesInfo
+	// playerClasses are the player list entries counted by class.
+	playerClasses map[string]int
+	// sessions are the sessions of the players in playerInfo, in the same order, and scoreTotals are the scores
+	// accumulated by each session. They are taken with the query so that they match the player list.
+	sessions    []playerSession
+	scoreTotals map[uint64]float64
+}
+
+// query queries the A2S server within the scope and records the outcome in the Status. The player list and rules
+// from the previous query are kept in the Status if they are out of scope. The player list entries are also counted by
+// class, before excluded players are removed and the names are anonymized, so that the counts can be compared with the
+// server info.
+func (c *Collector) query(scope Scope) queryResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	serverInfo, playerInfo, err := c.queryInfo(!scope.Players)
+	c.normalizePlayers(playerInfo)
+	playerClasses := c.countPlayerClasses(playerInfo)
+	c.excludePlayers(playerInfo)
+	c.anonymizePlayers(playerInfo)
+
+	var rules *a2s.RulesInfo
+	if serverInfo != nil && scope.Rules {
+		var rulesErr error
+		rules, rulesErr = c.client.QueryRules()
+		if rulesErr != nil {
+			fmt.Fprintln(os.Stderr, "Could not query rules:", rulesErr)
+			rules = nil
+			if err == nil {
+				err = rulesErr
+			}
+		}
+	}
+
+	prevStatus := c.status
+	if prevStatus.Time.IsZero() {
+		prevStatus = c.restoredStatus
+	}
+
+	status := Status{
+		Time:       time.Now(),
+		ServerUp:   serverInfo != nil,
+		PlayerUp:   playerInfo != nil,
+		Err:        err,
+		ServerInfo: serverInfo,
+		PlayerInfo: playerInfo,
+		Rules:      rules,
+	}
+	if serverInfo != nil {
+		status.ServerName = serverInfo.Name
+	}
+
+	// Changes are only observed in what was queried.
+	c.observe(prevStatus, status)
+
+	if !scope.Players {
+		status.PlayerUp = prevStatus.PlayerUp
+		status.PlayerInfo = prevStatus.PlayerInfo
+	}
+	if !scope.Rules {
+		status.Rules = prevStatus.Rules
+	}
+
+	c.statusMu.Lock()
+	c.status = status
+	c.statusMu.Unlock()
+
+	result := queryResult{serverInfo: serverInfo, playerInfo: playerInfo, rules: rules, playerClasses: playerClasses}
+	if playerInfo != nil {
+		result.sessions = append([]playerSession(nil), c.sessions.active...)
+		result.scoreTotals = c.rounds.scoreTotals()
+	}
+
+	return result
+}
 
+// queryInfo queries the A2S server over UDP. Failure will result in one or both of the info return values being nil.
+func (c *Collector) queryInfo(excludePlayerMetrics bool) (serverInfo *a2s.ServerInfo, playerInfo *a2s.PlayerInfo, err error) {
 	// Lazy initialization of UDP client.
 	if c.client == nil {
 		c.client, err = a2s.NewClient(c.addr, c.clientOptions...)
 		if err != nil {
-			fmt.Println("Could not create A2S client:", err)
+			fmt.Fprintln(os.Stderr, "Could not create A2S client:", err)
 			return
 		}
 	}
@@ -115,7 +521,8 @@ func (c *Collector) queryInfo(excludePlayerMetrics bool) (serverInfo *a2s.Server
 	// Query server info.
 	serverInfo, err = c.client.QueryInfo()
 	if err != nil {
-		fmt.Println("Could not query server info:", err)
+		fmt.Fprintln(os.Stderr, "Could not query server info:", err)
+		serverInfo = nil
 		return
 	}
 
@@ -131,9 +538,10 @@ func (c *Collector) queryInfo(excludePlayerMetrics bool) (serverInfo *a2s.Server
 		options = append(options, c.clientOptions...)
 		playerClient, err = a2s.NewClient(c.addr, options...)
 		if err != nil {
-			fmt.Println("Could not create A2S client for The Ship player query:", err)
+			fmt.Fprintln(os.Stderr, "Could not create A2S client for The Ship player query:", err)
 			return
 		}
+		defer playerClient.Close()
 	}
 
 	// Query player info.
@@ -141,7 +549,8 @@ func (c *Collector) queryInfo(excludePlayerMetrics bool) (serverInfo *a2s.Server
 	if serverInfo.ServerType != a2s.ServerType_SourceTV {
 		playerInfo, err = playerClient.QueryPlayer()
 		if err != nil {
-			fmt.Println("Could not query player info:", err)
+			fmt.Fprintln(os.Stderr, "Could not query player info:", err)
+			playerInfo = nil
 			return
 		}
 	}
@@ -161,20 +570,37 @@ func (c *Collector) collectServerInfo(serverInfo *a2s.ServerInfo, add adder) {
 		return do()
 	}
 
-	add("server_info", 1,
-		serverInfo.Map,
-		serverInfo.Folder,
-		serverInfo.Game,
-		serverInfo.ServerType.String(),
-		serverInfo.ServerOS.String(),
-		serverInfo.Version,
-		fmt.Sprintf("%d", serverInfo.ID),
-		nilSafe(serverInfo.ExtendedServerInfo, func() string { return serverInfo.ExtendedServerInfo.Keywords }),
-		nilSafe(serverInfo.ExtendedServerInfo, func() string { return fmt.Sprintf("%d", serverInfo.ExtendedServerInfo.GameID) }),
-		nilSafe(serverInfo.ExtendedServerInfo, func() string { return fmt.Sprintf("%d", serverInfo.ExtendedServerInfo.SteamID) }),
-		nilSafe(serverInfo.TheShip, func() string { return serverInfo.TheShip.Mode.String() }),
-		nilSafe(serverInfo.SourceTV, func() string { return serverInfo.SourceTV.Name }),
-	)
+	if c.infoMode.split() {
+		c.collectSplitInfo(serverInfo, add)
+	}
+
+	if !containsString(c.serverLabels, "server_name") {
+		add("server_name_info", 1, serverInfo.Name)
+	}
+
+	if c.infoMode.combined() {
+		values := map[string]string{
+			"server_name":     serverInfo.Name,
+			"map":             serverInfo.Map,
+			"folder":          serverInfo.Folder,
+			"game":            serverInfo.Game,
+			"server_type":     serverInfo.ServerType.String(),
+			"server_os":       serverInfo.ServerOS.String(),
+			"version":         serverInfo.Version,
+			"server_id":       fmt.Sprintf("%d", serverInfo.ID),
+			"keywords":        nilSafe(serverInfo.ExtendedServerInfo, func() string { return serverInfo.ExtendedServerInfo.Keywords }),
+			"server_game_id":  nilSafe(serverInfo.ExtendedServerInfo, func() string { return fmt.Sprintf("%d", serverInfo.ExtendedServerInfo.GameID) }),
+			"server_steam_id": nilSafe(serverInfo.ExtendedServerInfo, func() string { return fmt.Sprintf("%d", serverInfo.ExtendedServerInfo.SteamID) }),
+			"the_ship_mode":   nilSafe(serverInfo.TheShip, func() string { return serverInfo.TheShip.Mode.String() }),
+			"source_tv_name":  nilSafe(serverInfo.SourceTV, func() string { return serverInfo.SourceTV.Name }),
+		}
+
+		labelValues := make([]string, 0, len(c.infoLabels))
+		for _, label := range c.infoLabels {
+			labelValues = append(labelValues, values[label])
+		}
+		add("server_info", 1, labelValues...)
+	}
 
 	addPos := func(name string, value float64, labelValues ...string) {
 		if value <= 0 {
@@ -212,19 +638,42 @@ func (c *Collector) collectServerInfo(serverInfo *a2s.ServerInfo, add adder) {
 	}
 }
 
-func (c *Collector) collectPlayerInfo(playerInfo *a2s.PlayerInfo, add adder) {
+func (c *Collector) collectPlayerInfo(result queryResult, add adder) {
+	playerInfo := result.playerInfo
 	if playerInfo == nil {
 		return
 	}
 
 	add("player_count", float64(playerInfo.Count))
 
-	for _, player := range c.uniquePlayers(playerInfo.Players) {
+	if !c.playerMode.labeled() || c.playerNameMode == PlayerNameOmitted {
+		return
+	}
+
+	unique, sources, duplicates := c.resolveDuplicates(playerInfo.Players, result.sessions)
+	add("player_duplicate_names", float64(duplicates))
+
+	players, truncated := c.limitPlayers(unique)
+	if c.maxPlayerSeries > 0 {
+		add("players_truncated", float64(truncated))
+	}
+
+	for _, player := range players {
 		labelValues := []string{player.Name, fmt.Sprintf("%d", player.Index)}
 
 		add("player_info", 1, labelValues...)
 		add("player_duration", float64(player.Duration), labelValues...)
 		add("player_score", float64(player.Score), labelValues...)
+		// The total of an aggregated player is the sum of the players behind the name.
+		var total float64
+		for _, index := range sources[player.Name] {
+			total += result.scoreTotals[result.sessions[index].id]
+		}
+		add("player_score_total", total, labelValues...)
+
+		if c.duplicateMode == DuplicateAggregate {
+			add("player_instances", float64(len(sources[player.Name])), labelValues...)
+		}
 
 		if player.TheShip != nil {
 			add("player_the_ship_deaths", float64(player.TheShip.Deaths), labelValues...)
@@ -233,21 +682,11 @@ func (c *Collector) collectPlayerInfo(playerInfo *a2s.PlayerInfo, add adder) {
 	}
 }
 
-func (c *Collector) uniquePlayers(players []*a2s.Player) []*a2s.Player {
-	// Some servers like Rust will assign a pool of random player names, which may contain duplicates
-	// and cause errors in the Prometheus registry.
-
-	result := make([]*a2s.Player, 0, len(players))
-	seen := make(map[string]struct{})
-
-	for _, player := range players {
-		if _, ok := seen[player.Name]; ok {
-			continue
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
 		}
-
-		seen[player.Name] = struct{}{}
-		result = append(result, player)
 	}
-
-	return result
+	return false
 }
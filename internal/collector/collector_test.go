@@ -19,7 +19,7 @@ import (
 // TestCollector_Describe_PrintTable tests the Describe function.
 // It also prints a Markdown-formatted table of all registered metrics, which can be copied to the README.
 func TestCollector_Describe_PrintTable(t *testing.T) {
-	c := collector.New("", "", false)
+	c := collector.New("", "", collector.Options{})
 	descs := testDescribe(c)
 	if len(descs) == 0 {
 		t.Error("expected Descs but got none")
@@ -98,7 +98,7 @@ func TestCollector(t *testing.T) {
 
 	// Set up the registry and gather metrics from the test A2S server.
 	registry := prometheus.NewPedanticRegistry()
-	registry.MustRegister(collector.New("", conn.LocalAddr().String(), false))
+	registry.MustRegister(collector.New("", conn.LocalAddr().String(), collector.Options{}))
 	metrics, err := registry.Gather()
 	if err != nil {
 		t.Fatal(err)
@@ -164,7 +164,7 @@ func TestCollector_ExcludePlayerMetrics(t *testing.T) {
 
 	// Set up the registry and gather metrics from the test A2S server.
 	registry := prometheus.NewPedanticRegistry()
-	registry.MustRegister(collector.New("", conn.LocalAddr().String(), true))
+	registry.MustRegister(collector.New("", conn.LocalAddr().String(), collector.Options{ExcludePlayerMetrics: true}))
 	metrics, err := registry.Gather()
 	if err != nil {
 		t.Fatal(err)
@@ -186,6 +186,23 @@ func TestCollector_ExcludePlayerMetrics(t *testing.T) {
 	}
 }
 
+// testServe runs a test A2S server and returns its address.
+func testServe(t *testing.T, srv *testserver.TestServer) string {
+	t.Helper()
+
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	go func() {
+		_ = srv.Serve(conn)
+	}()
+
+	return conn.LocalAddr().String()
+}
+
 type expectGauge struct {
 	value  float64
 	labels map[string]string
@@ -0,0 +1,109 @@
+package collector
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/rumblefrog/go-a2s"
+)
+
+// PlayerMode selects how player metrics are exported.
+type PlayerMode string
+
+const (
+	// PlayerModeLabeled exports a series per player, labeled with the player name.
+	PlayerModeLabeled PlayerMode = "labeled"
+	// PlayerModeAggregated exports distributions across the player list, without per-player labels.
+	PlayerModeAggregated PlayerMode = "aggregated"
+	// PlayerModeBoth exports both.
+	PlayerModeBoth PlayerMode = "both"
+)
+
+// ParsePlayerMode validates a PlayerMode. An empty string is PlayerModeLabeled.
+func ParsePlayerMode(s string) (PlayerMode, error) {
+	switch mode := PlayerMode(s); mode {
+	case "":
+		return PlayerModeLabeled, nil
+	case PlayerModeLabeled, PlayerModeAggregated, PlayerModeBoth:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown player mode %q", s)
+	}
+}
+
+func (m PlayerMode) labeled() bool {
+	return m == "" || m == PlayerModeLabeled || m == PlayerModeBoth
+}
+
+func (m PlayerMode) aggregated() bool {
+	return m == PlayerModeAggregated || m == PlayerModeBoth
+}
+
+// Histogram buckets of the player distributions.
+var (
+	playerDurationBuckets = []float64{60, 300, 600, 1800, 3600, 2 * 3600, 4 * 3600, 8 * 3600}
+	playerScoreBuckets    = []float64{0, 1, 5, 10, 25, 50, 100, 250, 500, 1000}
+)
+
+// collectPlayerDistribution exports the distribution of duration and score across the player list, which bounds the
+// number of series no matter how many players are connected.
+func (c *Collector) collectPlayerDistribution(playerInfo *a2s.PlayerInfo, add adder, addHistogram histogramAdder) {
+	if playerInfo == nil {
+		return
+	}
+
+	durations := make([]float64, 0, len(playerInfo.Players))
+	scores := make([]float64, 0, len(playerInfo.Players))
+
+	for _, player := range playerInfo.Players {
+		durations = append(durations, float64(player.Duration))
+		scores = append(scores, float64(player.Score))
+	}
+
+	// The metric names are the prefix followed by a statistic and the unit, for example player_duration_max_seconds.
+	addDistribution := func(prefix, unit string, values, buckets []float64) {
+		count, sum, counts := histogram(values, buckets)
+		addHistogram(prefix+"_distribution"+unit, count, sum, counts)
+
+		if len(values) == 0 {
+			return
+		}
+
+		sort.Float64s(values)
+		add(prefix+"_min"+unit, values[0])
+		add(prefix+"_max"+unit, values[len(values)-1])
+		add(prefix+"_median"+unit, median(values))
+	}
+
+	addDistribution("player_duration", "_seconds", durations, playerDurationBuckets)
+	addDistribution("player_score", "", scores, playerScoreBuckets)
+}
+
+// histogram returns the count, sum and cumulative bucket counts of the values.
+func histogram(values, buckets []float64) (count uint64, sum float64, counts map[float64]uint64) {
+	counts = make(map[float64]uint64, len(buckets))
+	for _, bucket := range buckets {
+		counts[bucket] = 0
+	}
+
+	for _, v := range values {
+		count++
+		sum += v
+		for _, bucket := range buckets {
+			if v <= bucket {
+				counts[bucket]++
+			}
+		}
+	}
+
+	return count, sum, counts
+}
+
+// median returns the median of sorted values, which must not be empty.
+func median(sorted []float64) float64 {
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid]
+	}
+	return (sorted[mid-1] + sorted[mid]) / 2
+}
@@ -0,0 +1,75 @@
+package collector_test
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rumblefrog/go-a2s"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+	"github.com/armsnyder/a2s-exporter/internal/testserver"
+)
+
+func TestCollector_PlayerModeAggregated(t *testing.T) {
+	srv := &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo"},
+		PlayerInfo: &a2s.PlayerInfo{Count: 4, Players: []*a2s.Player{
+			{Name: "jon", Duration: 30, Score: 1},
+			{Name: "alice", Duration: 600, Score: 20},
+			{Name: "alice", Duration: 90, Score: 4},
+			{Name: "bob", Duration: 7200, Score: 0},
+		}},
+	}
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector.New("", testServe(t, srv), collector.Options{PlayerMode: collector.PlayerModeAggregated}))
+	metrics := testGather(t, registry)
+
+	testAssertValue(t, metrics, "player_count", 4)
+	testAssertValue(t, metrics, "player_duration_distribution_seconds", 4)
+	testAssertValue(t, metrics, "player_duration_min_seconds", 30)
+	testAssertValue(t, metrics, "player_duration_max_seconds", 7200)
+	testAssertValue(t, metrics, "player_duration_median_seconds", 345)
+	testAssertValue(t, metrics, "player_score_distribution", 4)
+	testAssertValue(t, metrics, "player_score_min", 0)
+	testAssertValue(t, metrics, "player_score_max", 20)
+	testAssertValue(t, metrics, "player_score_median", 2.5)
+
+	// No per-player series.
+	for _, family := range metrics {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "player_name" {
+					t.Errorf("metric %s should not have a player_name label", family.GetName())
+				}
+			}
+		}
+	}
+}
+
+func TestCollector_PlayerModeBoth(t *testing.T) {
+	srv := &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo"},
+		PlayerInfo: &a2s.PlayerInfo{Count: 1, Players: []*a2s.Player{
+			{Name: "jon", Duration: 30, Score: 1},
+		}},
+	}
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector.New("", testServe(t, srv), collector.Options{PlayerMode: collector.PlayerModeBoth}))
+	metrics := testGather(t, registry)
+
+	testAssertValue(t, metrics, "player_duration_median_seconds", 30)
+	testAssertGauge(t, metrics, "player_duration",
+		expectGauge{value: 30, labels: map[string]string{"server_name": "foo", "player_name": "jon"}},
+	)
+}
+
+func TestParsePlayerMode(t *testing.T) {
+	for _, s := range []string{"", "labeled", "aggregated", "both"} {
+		if _, err := collector.ParsePlayerMode(s); err != nil {
+			t.Errorf("%q: %v", s, err)
+		}
+	}
+	if _, err := collector.ParsePlayerMode("nope"); err == nil {
+		t.Error("expected an error")
+	}
+}
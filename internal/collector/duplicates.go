@@ -0,0 +1,116 @@
+package collector
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/rumblefrog/go-a2s"
+)
+
+// DuplicateMode selects how players with the same name are exported with per-player labels, since duplicate label
+// values would cause errors in the Prometheus registry. Some servers like Rust assign a pool of random player names,
+// which may contain duplicates.
+type DuplicateMode string
+
+const (
+	// DuplicateDrop keeps the first player with each name and drops the rest.
+	DuplicateDrop DuplicateMode = "drop"
+	// DuplicateSuffix keeps every player, appending a suffix such as "#2" to the name of each duplicate. Players keep
+	// their suffix while they stay connected, and a player who joins takes the lowest number which is free.
+	DuplicateSuffix DuplicateMode = "suffix"
+	// DuplicateAggregate combines players with the same name into one, summing their scores and taking the longest
+	// duration. The player_instances metric counts the players behind each name.
+	DuplicateAggregate DuplicateMode = "aggregate"
+)
+
+// ParseDuplicateMode validates a DuplicateMode. An empty string is DuplicateDrop.
+func ParseDuplicateMode(s string) (DuplicateMode, error) {
+	switch mode := DuplicateMode(s); mode {
+	case "":
+		return DuplicateDrop, nil
+	case DuplicateDrop, DuplicateSuffix, DuplicateAggregate:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown duplicate player mode %q", s)
+	}
+}
+
+// resolveDuplicates returns players with unique names according to the duplicate mode, the indexes of the players
+// behind each name, and the number of players whose name was already taken by another player. The sessions are of
+// the players, in the same order, and number the duplicates.
+func (c *Collector) resolveDuplicates(players []*a2s.Player, sessions []playerSession) (unique []*a2s.Player, sources map[string][]int, duplicates int) {
+	// Group the players by name, keeping the server's order of first appearance.
+	var names []string
+	groups := make(map[string][]int)
+
+	for i, player := range players {
+		if _, ok := groups[player.Name]; !ok {
+			names = append(names, player.Name)
+		}
+		groups[player.Name] = append(groups[player.Name], i)
+	}
+
+	sources = make(map[string][]int, len(players))
+	add := func(player *a2s.Player, indexes ...int) {
+		// A suffixed name could collide with a real player's name, so drop any remaining duplicates.
+		if _, ok := sources[player.Name]; ok {
+			return
+		}
+		unique = append(unique, player)
+		sources[player.Name] = indexes
+	}
+
+	for _, name := range names {
+		group := groups[name]
+		duplicates += len(group) - 1
+
+		switch c.duplicateMode {
+		case DuplicateSuffix:
+			sort.SliceStable(group, func(i, j int) bool { return sessions[group[i]].suffix < sessions[group[j]].suffix })
+			for _, index := range group {
+				player := players[index]
+				if suffix := sessions[index].suffix; suffix > 1 {
+					renamed := *player
+					renamed.Name = fmt.Sprintf("%s#%d", name, suffix)
+					player = &renamed
+				}
+				add(player, index)
+			}
+
+		case DuplicateAggregate:
+			grouped := make([]*a2s.Player, 0, len(group))
+			for _, index := range group {
+				grouped = append(grouped, players[index])
+			}
+			add(aggregatePlayers(grouped), group...)
+
+		default:
+			add(players[group[0]], group[0])
+		}
+	}
+
+	return unique, sources, duplicates
+}
+
+// aggregatePlayers combines players with the same name into a new Player.
+func aggregatePlayers(group []*a2s.Player) *a2s.Player {
+	combined := *group[0]
+
+	if group[0].TheShip != nil {
+		theShip := *group[0].TheShip
+		combined.TheShip = &theShip
+	}
+
+	for _, player := range group[1:] {
+		combined.Score += player.Score
+		if player.Duration > combined.Duration {
+			combined.Duration = player.Duration
+		}
+		if player.TheShip != nil && combined.TheShip != nil {
+			combined.TheShip.Deaths += player.TheShip.Deaths
+			combined.TheShip.Money += player.TheShip.Money
+		}
+	}
+
+	return &combined
+}
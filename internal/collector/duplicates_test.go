@@ -0,0 +1,137 @@
+package collector_test
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rumblefrog/go-a2s"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+	"github.com/armsnyder/a2s-exporter/internal/testserver"
+)
+
+func TestCollector_DuplicateMode(t *testing.T) {
+	addr := testServe(t, &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo"},
+		PlayerInfo: &a2s.PlayerInfo{Count: 4, Players: []*a2s.Player{
+			{Name: "alice", Duration: 64, Score: 3},
+			{Name: "jon", Duration: 32, Score: 1},
+			{Name: "alice", Duration: 99, Score: 5},
+			{Name: "alice", Duration: 10, Score: 2},
+		}},
+	})
+
+	tests := []struct {
+		name          string
+		mode          collector.DuplicateMode
+		wantDuration  []expectGauge
+		wantScore     []expectGauge
+		wantInstances []expectGauge
+	}{
+		{
+			name: "drop",
+			mode: collector.DuplicateDrop,
+			wantDuration: []expectGauge{
+				{value: 64, labels: map[string]string{"player_name": "alice"}},
+				{value: 32, labels: map[string]string{"player_name": "jon"}},
+			},
+		},
+		{
+			name: "suffix",
+			mode: collector.DuplicateSuffix,
+			wantDuration: []expectGauge{
+				{value: 99, labels: map[string]string{"player_name": "alice"}},
+				{value: 64, labels: map[string]string{"player_name": "alice#2"}},
+				{value: 10, labels: map[string]string{"player_name": "alice#3"}},
+				{value: 32, labels: map[string]string{"player_name": "jon"}},
+			},
+		},
+		{
+			name: "aggregate",
+			mode: collector.DuplicateAggregate,
+			wantDuration: []expectGauge{
+				{value: 99, labels: map[string]string{"player_name": "alice"}},
+				{value: 32, labels: map[string]string{"player_name": "jon"}},
+			},
+			wantScore: []expectGauge{
+				{value: 10, labels: map[string]string{"player_name": "alice"}},
+				{value: 1, labels: map[string]string{"player_name": "jon"}},
+			},
+			wantInstances: []expectGauge{
+				{value: 3, labels: map[string]string{"player_name": "alice"}},
+				{value: 1, labels: map[string]string{"player_name": "jon"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := prometheus.NewPedanticRegistry()
+			registry.MustRegister(collector.New("", addr, collector.Options{DuplicateMode: tt.mode}))
+			metrics := testGather(t, registry)
+
+			testAssertValue(t, metrics, "player_duplicate_names", 2)
+			testAssertGauge(t, metrics, "player_duration", tt.wantDuration...)
+			if tt.wantScore != nil {
+				testAssertGauge(t, metrics, "player_score", tt.wantScore...)
+			}
+			if tt.wantInstances != nil {
+				testAssertGauge(t, metrics, "player_instances", tt.wantInstances...)
+			}
+		})
+	}
+}
+
+func TestCollector_DuplicateSuffix_Sticky(t *testing.T) {
+	srv := &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo"},
+		PlayerInfo: &a2s.PlayerInfo{Count: 2, Players: []*a2s.Player{
+			{Name: "alice", Duration: 100, Score: 5},
+			{Name: "alice", Duration: 50, Score: 3},
+		}},
+	}
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector.New("", testServe(t, srv), collector.Options{DuplicateMode: collector.DuplicateSuffix}))
+
+	metrics := testGather(t, registry)
+	testAssertGauge(t, metrics, "player_duration",
+		expectGauge{value: 100, labels: map[string]string{"player_name": "alice"}},
+		expectGauge{value: 50, labels: map[string]string{"player_name": "alice#2"}},
+	)
+	testAssertPlayerCounters(t, metrics, "player_score_total", map[string]float64{"alice": 5, "alice#2": 3})
+
+	// The first alice leaves, and the second keeps both her suffix and her own score total.
+	srv.Update(func() {
+		srv.PlayerInfo = &a2s.PlayerInfo{Count: 1, Players: []*a2s.Player{
+			{Name: "alice", Duration: 50, Score: 4},
+		}}
+	})
+	metrics = testGather(t, registry)
+	testAssertGauge(t, metrics, "player_duration", expectGauge{value: 50, labels: map[string]string{"player_name": "alice#2"}})
+	testAssertPlayerCounters(t, metrics, "player_score_total", map[string]float64{"alice#2": 4})
+
+	// A new alice takes the free name.
+	srv.Update(func() {
+		srv.PlayerInfo = &a2s.PlayerInfo{Count: 2, Players: []*a2s.Player{
+			{Name: "alice", Duration: 50, Score: 4},
+			{Name: "alice", Duration: 1, Score: 0},
+		}}
+	})
+	metrics = testGather(t, registry)
+	testAssertGauge(t, metrics, "player_duration",
+		expectGauge{value: 1, labels: map[string]string{"player_name": "alice"}},
+		expectGauge{value: 50, labels: map[string]string{"player_name": "alice#2"}},
+	)
+	testAssertPlayerCounters(t, metrics, "player_score_total", map[string]float64{"alice": 0, "alice#2": 4})
+}
+
+func TestParseDuplicateMode(t *testing.T) {
+	for _, s := range []string{"", "drop", "suffix", "aggregate"} {
+		if _, err := collector.ParseDuplicateMode(s); err != nil {
+			t.Errorf("%q: %v", s, err)
+		}
+	}
+	if _, err := collector.ParseDuplicateMode("nope"); err == nil {
+		t.Error("expected an error")
+	}
+}
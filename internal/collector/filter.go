@@ -0,0 +1,75 @@
+package collector
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// includeMetric reports whether the metric passes the include and exclude patterns of the Options.
+func includeMetric(name string, include, exclude []*regexp.Regexp) bool {
+	if len(include) > 0 && !matchesAny(name, include) {
+		return false
+	}
+	return !matchesAny(name, exclude)
+}
+
+func matchesAny(s string, patterns []*regexp.Regexp) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// dropLabels removes the dropped labels from labels. It returns the remaining labels, and the indices of the remaining
+// labels so that the label values can be filtered the same way. The indices are nil if no label was dropped.
+func dropLabels(labels, dropped []string) ([]string, []int) {
+	if len(dropped) == 0 {
+		return labels, nil
+	}
+
+	var kept []string
+	var indices []int
+	for i, label := range labels {
+		if !containsString(dropped, label) {
+			kept = append(kept, label)
+			indices = append(indices, i)
+		}
+	}
+
+	if len(kept) == len(labels) {
+		return labels, nil
+	}
+	return kept, indices
+}
+
+// keptLabelValues filters the label values of a metric whose labels were dropped.
+func (c *Collector) keptLabelValues(name string, labelValues []string) []string {
+	indices, ok := c.keptLabels[name]
+	if !ok {
+		return labelValues
+	}
+
+	kept := make([]string, 0, len(indices))
+	for _, i := range indices {
+		kept = append(kept, labelValues[i])
+	}
+	return kept
+}
+
+// ParseDropLabels parses label drops given as metric=label, for example server_info=keywords.
+func ParseDropLabels(s []string) (map[string][]string, error) {
+	drops := make(map[string][]string)
+
+	for _, v := range s {
+		metric, label, ok := strings.Cut(v, "=")
+		if !ok || metric == "" || label == "" {
+			return nil, fmt.Errorf("label drop %q is not in the form metric=label", v)
+		}
+		drops[metric] = append(drops[metric], label)
+	}
+
+	return drops, nil
+}
@@ -0,0 +1,95 @@
+package collector_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rumblefrog/go-a2s"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+	"github.com/armsnyder/a2s-exporter/internal/testserver"
+)
+
+func TestCollector_IncludeExcludeMetrics(t *testing.T) {
+	srv := &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo", Players: 1},
+		PlayerInfo: &a2s.PlayerInfo{Count: 1, Players: []*a2s.Player{{Name: "jon", Duration: 30}}},
+	}
+	c := collector.New("", testServe(t, srv), collector.Options{
+		IncludeMetrics: []*regexp.Regexp{regexp.MustCompile(`^(?:server_.*)$`)},
+		ExcludeMetrics: []*regexp.Regexp{regexp.MustCompile(`^(?:server_info)$`)},
+	})
+
+	for _, desc := range testDescribe(c) {
+		if matched, _ := regexp.MatchString(`fqName: "(server_info|player_.*)"`, desc.String()); matched {
+			t.Errorf("metric should have been filtered out: %s", desc)
+		}
+	}
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(c)
+	metrics := testGather(t, registry)
+
+	testAssertValue(t, metrics, "server_up", 1)
+	testAssertValue(t, metrics, "server_players", 1)
+	for _, family := range metrics {
+		switch family.GetName() {
+		case "server_info", "player_count", "player_score":
+			t.Errorf("metric %s should have been filtered out", family.GetName())
+		}
+	}
+}
+
+func TestCollector_DropLabels(t *testing.T) {
+	srv := &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{
+			Name:               "foo",
+			Map:                "de_dust2",
+			ExtendedServerInfo: &a2s.ExtendedServerInfo{Keywords: "secure"},
+		},
+		PlayerInfo: &a2s.PlayerInfo{Count: 1, Players: []*a2s.Player{{Name: "jon", Duration: 30}}},
+	}
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector.New("", testServe(t, srv), collector.Options{
+		DropLabels: map[string][]string{
+			"server_info":     {"keywords", "source_tv_name"},
+			"player_duration": {"player_index"},
+		},
+	}))
+	metrics := testGather(t, registry)
+
+	testAssertGauge(t, metrics, "server_info",
+		expectGauge{value: 1, labels: map[string]string{"server_name": "foo", "map": "de_dust2"}},
+	)
+	testAssertGauge(t, metrics, "player_duration",
+		expectGauge{value: 30, labels: map[string]string{"server_name": "foo", "player_name": "jon"}},
+	)
+
+	dropped := map[string]string{"server_info": "keywords", "player_duration": "player_index"}
+	for _, family := range metrics {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if dropped[family.GetName()] == label.GetName() {
+					t.Errorf("label %s should have been dropped from %s", label.GetName(), family.GetName())
+				}
+			}
+		}
+	}
+}
+
+func TestParseDropLabels(t *testing.T) {
+	drops, err := collector.ParseDropLabels([]string{"server_info=keywords", "server_info=map", "player_score=player_index"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drops["server_info"]) != 2 || drops["player_score"][0] != "player_index" {
+		t.Errorf("unexpected drops %v", drops)
+	}
+
+	for _, s := range []string{"server_info", "=keywords", "server_info="} {
+		if _, err := collector.ParseDropLabels([]string{s}); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package collector
+
+import (
+	"fmt"
+
+	"github.com/rumblefrog/go-a2s"
+)
+
+// IdentityLabel selects a label which identifies the server on every metric.
+type IdentityLabel string
+
+const (
+	// IdentityName labels the metrics with server_name, the name reported by the server. It changes whenever the
+	// server is renamed.
+	IdentityName IdentityLabel = "name"
+	// IdentityAddress labels the metrics with server_address, the query address of the server.
+	IdentityAddress IdentityLabel = "address"
+	// IdentityAlias labels the metrics with server_alias, the alias given to the server in the Options.
+	IdentityAlias IdentityLabel = "alias"
+	// IdentitySteamID labels the metrics with server_steam_id, the Steam ID reported by the server.
+	IdentitySteamID IdentityLabel = "steam_id"
+)
+
+// ParseIdentityLabels validates a list of IdentityLabels. An empty list is IdentityName alone.
+func ParseIdentityLabels(s []string) ([]IdentityLabel, error) {
+	if len(s) == 0 {
+		return []IdentityLabel{IdentityName}, nil
+	}
+
+	labels := make([]IdentityLabel, 0, len(s))
+	seen := make(map[IdentityLabel]bool, len(s))
+
+	for _, v := range s {
+		switch label := IdentityLabel(v); label {
+		case IdentityName, IdentityAddress, IdentityAlias, IdentitySteamID:
+			if seen[label] {
+				return nil, fmt.Errorf("duplicate identity label %q", v)
+			}
+			seen[label] = true
+			labels = append(labels, label)
+		default:
+			return nil, fmt.Errorf("unknown identity label %q", v)
+		}
+	}
+
+	return labels, nil
+}
+
+// serverInfoLabels are the info labels of server_info. Any of them which are also identity labels are left out, since
+// the identity labels come first on every metric.
+var serverInfoLabels = []string{"server_name", "map", "folder", "game", "server_type", "server_os", "version", "server_id", "keywords", "server_game_id", "server_steam_id", "the_ship_mode", "source_tv_name"}
+
+// identity splits the identity labels into constant labels, which are known up front, and the names of the labels
+// whose values come from the server info.
+func identity(labels []IdentityLabel, addr, alias string) (constLabels map[string]string, serverLabels []string) {
+	if len(labels) == 0 {
+		labels = []IdentityLabel{IdentityName}
+	}
+
+	constLabels = make(map[string]string)
+
+	for _, label := range labels {
+		switch label {
+		case IdentityName:
+			serverLabels = append(serverLabels, "server_name")
+		case IdentityAddress:
+			constLabels["server_address"] = addr
+		case IdentityAlias:
+			constLabels["server_alias"] = alias
+		case IdentitySteamID:
+			serverLabels = append(serverLabels, "server_steam_id")
+		}
+	}
+
+	return constLabels, serverLabels
+}
+
+// identityValues returns the values of the identity labels which come from the server info.
+func (c *Collector) identityValues(serverInfo *a2s.ServerInfo) []string {
+	values := make([]string, 0, len(c.serverLabels))
+
+	for _, label := range c.serverLabels {
+		switch label {
+		case "server_name":
+			values = append(values, serverInfo.Name)
+		case "server_steam_id":
+			var id string
+			if steamID(serverInfo) != 0 {
+				id = fmt.Sprintf("%d", steamID(serverInfo))
+			}
+			values = append(values, id)
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,78 @@
+package collector_test
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rumblefrog/go-a2s"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+	"github.com/armsnyder/a2s-exporter/internal/testserver"
+)
+
+func TestCollector_IdentityLabels(t *testing.T) {
+	srv := &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{
+			Name:               "foo (wiped 2024-01-02)",
+			Map:                "de_dust2",
+			ExtendedServerInfo: &a2s.ExtendedServerInfo{SteamID: 90071992547409920},
+		},
+	}
+	addr := testServe(t, srv)
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector.New("", addr, collector.Options{
+		IdentityLabels:       []collector.IdentityLabel{collector.IdentityAddress, collector.IdentityAlias, collector.IdentitySteamID},
+		Alias:                "eu-1",
+		ExcludePlayerMetrics: true,
+	}))
+	metrics := testGather(t, registry)
+
+	identity := map[string]string{"server_address": addr, "server_alias": "eu-1", "server_steam_id": "90071992547409920"}
+	withIdentity := func(labels map[string]string) map[string]string {
+		for k, v := range identity {
+			labels[k] = v
+		}
+		return labels
+	}
+
+	testAssertGauge(t, metrics, "server_info",
+		expectGauge{value: 1, labels: withIdentity(map[string]string{"server_name": "foo (wiped 2024-01-02)", "map": "de_dust2"})},
+	)
+	testAssertGauge(t, metrics, "server_name_info",
+		expectGauge{value: 1, labels: withIdentity(map[string]string{"server_name": "foo (wiped 2024-01-02)"})},
+	)
+	testAssertGauge(t, metrics, "server_players",
+		expectGauge{value: 0, labels: withIdentity(map[string]string{})},
+	)
+
+	// The server name is only an info label, and the constant identity labels are also on server_up.
+	for _, family := range metrics {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "server_name" && family.GetName() != "server_info" && family.GetName() != "server_name_info" {
+					t.Errorf("metric %s should not have a server_name label", family.GetName())
+				}
+			}
+		}
+	}
+	testAssertGauge(t, metrics, "server_up",
+		expectGauge{value: 1, labels: map[string]string{"server_address": addr, "server_alias": "eu-1"}},
+	)
+}
+
+func TestParseIdentityLabels(t *testing.T) {
+	labels, err := collector.ParseIdentityLabels(nil)
+	if err != nil || len(labels) != 1 || labels[0] != collector.IdentityName {
+		t.Errorf("wanted the default [name], got %v, %v", labels, err)
+	}
+
+	if _, err := collector.ParseIdentityLabels([]string{"name", "address", "alias", "steam_id"}); err != nil {
+		t.Error(err)
+	}
+	if _, err := collector.ParseIdentityLabels([]string{"nope"}); err == nil {
+		t.Error("expected an error for an unknown label")
+	}
+	if _, err := collector.ParseIdentityLabels([]string{"name", "name"}); err == nil {
+		t.Error("expected an error for a duplicate label")
+	}
+}
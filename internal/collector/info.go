@@ -0,0 +1,60 @@
+package collector
+
+import (
+	"fmt"
+
+	"github.com/rumblefrog/go-a2s"
+)
+
+// InfoMode selects how the non-numerical server info is exported.
+type InfoMode string
+
+const (
+	// InfoModeCombined exports server_info, with all the info in its labels.
+	InfoModeCombined InfoMode = "combined"
+	// InfoModeSplit exports separate info metrics, each with few labels, so that a change of one value such as the
+	// keywords only replaces the series of that metric.
+	InfoModeSplit InfoMode = "split"
+	// InfoModeBoth exports both.
+	InfoModeBoth InfoMode = "both"
+)
+
+// ParseInfoMode validates an InfoMode. An empty string is InfoModeCombined.
+func ParseInfoMode(s string) (InfoMode, error) {
+	switch mode := InfoMode(s); mode {
+	case "":
+		return InfoModeCombined, nil
+	case InfoModeCombined, InfoModeSplit, InfoModeBoth:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown info mode %q", s)
+	}
+}
+
+func (m InfoMode) combined() bool {
+	return m == "" || m == InfoModeCombined || m == InfoModeBoth
+}
+
+func (m InfoMode) split() bool {
+	return m == InfoModeSplit || m == InfoModeBoth
+}
+
+func (c *Collector) collectSplitInfo(serverInfo *a2s.ServerInfo, add adder) {
+	var gameID, keywords string
+	if serverInfo.ExtendedServerInfo != nil {
+		gameID = fmt.Sprintf("%d", serverInfo.ExtendedServerInfo.GameID)
+		keywords = serverInfo.ExtendedServerInfo.Keywords
+	}
+
+	add("server_version_info", 1, serverInfo.Version)
+	add("server_map_info", 1, serverInfo.Map)
+	add("server_game_info", 1,
+		serverInfo.Game,
+		serverInfo.Folder,
+		fmt.Sprintf("%d", serverInfo.ID),
+		gameID,
+		serverInfo.ServerType.String(),
+		serverInfo.ServerOS.String(),
+	)
+	add("server_keywords_info", 1, keywords)
+}
@@ -0,0 +1,77 @@
+package collector_test
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rumblefrog/go-a2s"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+	"github.com/armsnyder/a2s-exporter/internal/testserver"
+)
+
+func TestCollector_InfoModeSplit(t *testing.T) {
+	srv := &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{
+			Name:    "foo",
+			Map:     "de_dust2",
+			Folder:  "csgo",
+			Game:    "Counter-Strike",
+			ID:      730,
+			Version: "1.2.3",
+			ExtendedServerInfo: &a2s.ExtendedServerInfo{
+				Keywords: "secure,casual",
+				GameID:   730,
+			},
+		},
+	}
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector.New("", testServe(t, srv), collector.Options{InfoMode: collector.InfoModeSplit, ExcludePlayerMetrics: true}))
+	metrics := testGather(t, registry)
+
+	testAssertGauge(t, metrics, "server_version_info",
+		expectGauge{value: 1, labels: map[string]string{"server_name": "foo", "version": "1.2.3"}},
+	)
+	testAssertGauge(t, metrics, "server_map_info",
+		expectGauge{value: 1, labels: map[string]string{"server_name": "foo", "map": "de_dust2"}},
+	)
+	testAssertGauge(t, metrics, "server_game_info",
+		expectGauge{value: 1, labels: map[string]string{"server_name": "foo", "game": "Counter-Strike", "folder": "csgo", "server_id": "730", "server_game_id": "730"}},
+	)
+	testAssertGauge(t, metrics, "server_keywords_info",
+		expectGauge{value: 1, labels: map[string]string{"server_name": "foo", "keywords": "secure,casual"}},
+	)
+
+	for _, family := range metrics {
+		if family.GetName() == "server_info" {
+			t.Error("server_info should not be exported in split mode")
+		}
+	}
+}
+
+func TestCollector_InfoModeBoth(t *testing.T) {
+	srv := &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo", Map: "de_dust2"},
+	}
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector.New("", testServe(t, srv), collector.Options{InfoMode: collector.InfoModeBoth, ExcludePlayerMetrics: true}))
+	metrics := testGather(t, registry)
+
+	testAssertGauge(t, metrics, "server_info",
+		expectGauge{value: 1, labels: map[string]string{"server_name": "foo", "map": "de_dust2"}},
+	)
+	testAssertGauge(t, metrics, "server_map_info",
+		expectGauge{value: 1, labels: map[string]string{"server_name": "foo", "map": "de_dust2"}},
+	)
+}
+
+func TestParseInfoMode(t *testing.T) {
+	for _, s := range []string{"", "combined", "split", "both"} {
+		if _, err := collector.ParseInfoMode(s); err != nil {
+			t.Errorf("%q: %v", s, err)
+		}
+	}
+	if _, err := collector.ParseInfoMode("nope"); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,66 @@
+package collector
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var labelNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// reservedLabels are the names of the labels set by the collector itself, which extra labels must not override.
+var reservedLabels = func() map[string]bool {
+	reserved := map[string]bool{
+		"server_address":  true,
+		"server_alias":    true,
+		"server_steam_id": true,
+		"player_name":     true,
+		"player_index":    true,
+		"class":           true,
+		"kind":            true,
+		"window":          true,
+		"rule":            true,
+		// Set by Prometheus on histogram buckets and summary quantiles.
+		"le":       true,
+		"quantile": true,
+	}
+	for _, label := range serverInfoLabels {
+		reserved[label] = true
+	}
+	return reserved
+}()
+
+// ValidateLabels checks that extra labels have valid names which do not clash with the labels set by the collector.
+func ValidateLabels(labels map[string]string) error {
+	for name := range labels {
+		if !labelNamePattern.MatchString(name) || strings.HasPrefix(name, "__") {
+			return fmt.Errorf("invalid label name %q", name)
+		}
+		if reservedLabels[name] {
+			return fmt.Errorf("label name %q is reserved by the exporter", name)
+		}
+	}
+	return nil
+}
+
+// ParseLabels parses labels given as key=value, and validates them with ValidateLabels.
+func ParseLabels(s []string) (map[string]string, error) {
+	labels := make(map[string]string, len(s))
+
+	for _, v := range s {
+		name, value, ok := strings.Cut(v, "=")
+		if !ok {
+			return nil, fmt.Errorf("label %q is not in the form key=value", v)
+		}
+		if _, ok := labels[name]; ok {
+			return nil, fmt.Errorf("duplicate label %q", name)
+		}
+		labels[name] = value
+	}
+
+	if err := ValidateLabels(labels); err != nil {
+		return nil, err
+	}
+
+	return labels, nil
+}
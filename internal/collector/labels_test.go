@@ -0,0 +1,88 @@
+package collector_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rumblefrog/go-a2s"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+	"github.com/armsnyder/a2s-exporter/internal/testserver"
+)
+
+func TestCollector_Labels(t *testing.T) {
+	srv := &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo"},
+	}
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector.New("", testServe(t, srv), collector.Options{
+		Labels:               map[string]string{"env": "prod", "region": "eu"},
+		ExcludePlayerMetrics: true,
+	}))
+	metrics := testGather(t, registry)
+
+	testAssertGauge(t, metrics, "server_up",
+		expectGauge{value: 1, labels: map[string]string{"env": "prod", "region": "eu"}},
+	)
+	testAssertGauge(t, metrics, "server_players",
+		expectGauge{value: 0, labels: map[string]string{"server_name": "foo", "env": "prod", "region": "eu"}},
+	)
+}
+
+func TestParseLabels(t *testing.T) {
+	labels, err := collector.ParseLabels([]string{"env=prod", "game_mode=pvp=hard", "empty="})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if labels["env"] != "prod" || labels["game_mode"] != "pvp=hard" || labels["empty"] != "" {
+		t.Errorf("unexpected labels %v", labels)
+	}
+
+	for _, s := range []string{"env", "1env=prod", "__env=prod", "server_name=foo", "env=a,env=b"} {
+		if _, err := collector.ParseLabels(strings.Split(s, ",")); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+// TestValidateLabels_Reserved checks that every label set by the collector is reserved.
+func TestValidateLabels_Reserved(t *testing.T) {
+	c := collector.New("", "", collector.Options{
+		IdentityLabels: []collector.IdentityLabel{collector.IdentityName, collector.IdentityAddress, collector.IdentityAlias, collector.IdentitySteamID},
+		InfoMode:       collector.InfoModeBoth,
+	})
+
+	pattern := regexp.MustCompile(`constLabels: \{([^}]*)}, variableLabels: \{([^}]*)}`)
+	for _, desc := range testDescribe(c) {
+		match := pattern.FindStringSubmatch(desc.String())
+		if match == nil {
+			t.Errorf("failed pattern match for Desc %s", desc)
+			continue
+		}
+
+		var names []string
+		for _, constLabel := range strings.Split(match[1], ",") {
+			if name, _, ok := strings.Cut(constLabel, "="); ok {
+				names = append(names, name)
+			}
+		}
+		if match[2] != "" {
+			names = append(names, strings.Split(match[2], ",")...)
+		}
+
+		for _, name := range names {
+			if err := collector.ValidateLabels(map[string]string{name: "x"}); err == nil {
+				t.Errorf("label %s of %s should be reserved", name, desc)
+			}
+		}
+	}
+
+	// Histograms and summaries add these labels to their series.
+	for _, name := range []string{"le", "quantile"} {
+		if err := collector.ValidateLabels(map[string]string{name: "x"}); err == nil {
+			t.Errorf("label %s should be reserved", name)
+		}
+	}
+}
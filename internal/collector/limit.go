@@ -0,0 +1,51 @@
+package collector
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/rumblefrog/go-a2s"
+)
+
+// PlayerOrder selects which players are kept when the number of per-player series is limited.
+type PlayerOrder string
+
+const (
+	// PlayerOrderScore keeps the players with the highest score.
+	PlayerOrderScore PlayerOrder = "score"
+	// PlayerOrderDuration keeps the players who have been connected the longest.
+	PlayerOrderDuration PlayerOrder = "duration"
+)
+
+// ParsePlayerOrder validates a PlayerOrder. An empty string is PlayerOrderScore.
+func ParsePlayerOrder(s string) (PlayerOrder, error) {
+	switch order := PlayerOrder(s); order {
+	case "":
+		return PlayerOrderScore, nil
+	case PlayerOrderScore, PlayerOrderDuration:
+		return order, nil
+	default:
+		return "", fmt.Errorf("unknown player order %q", s)
+	}
+}
+
+// limitPlayers keeps the top players according to the configured order, if there are more than the configured
+// maximum. It returns the kept players and the number of players which were dropped.
+func (c *Collector) limitPlayers(players []*a2s.Player) ([]*a2s.Player, int) {
+	if c.maxPlayerSeries <= 0 || len(players) <= c.maxPlayerSeries {
+		return players, 0
+	}
+
+	sorted := make([]*a2s.Player, len(players))
+	copy(sorted, players)
+
+	// A stable sort keeps the server's order for ties, so that the kept players do not flap between scrapes.
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if c.playerOrder == PlayerOrderDuration {
+			return sorted[i].Duration > sorted[j].Duration
+		}
+		return sorted[i].Score > sorted[j].Score
+	})
+
+	return sorted[:c.maxPlayerSeries], len(players) - c.maxPlayerSeries
+}
@@ -0,0 +1,50 @@
+package collector_test
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rumblefrog/go-a2s"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+	"github.com/armsnyder/a2s-exporter/internal/testserver"
+)
+
+func TestCollector_MaxPlayerSeries(t *testing.T) {
+	srv := &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo"},
+		PlayerInfo: &a2s.PlayerInfo{Count: 4, Players: []*a2s.Player{
+			{Name: "jon", Duration: 30, Score: 1},
+			{Name: "alice", Duration: 600, Score: 20},
+			{Name: "bob", Duration: 7200, Score: 0},
+			{Name: "eve", Duration: 90, Score: 4},
+		}},
+	}
+	addr := testServe(t, srv)
+
+	tests := []struct {
+		name  string
+		order collector.PlayerOrder
+		want  []string
+	}{
+		{name: "score", order: collector.PlayerOrderScore, want: []string{"alice", "eve"}},
+		{name: "duration", order: collector.PlayerOrderDuration, want: []string{"bob", "alice"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := prometheus.NewPedanticRegistry()
+			registry.MustRegister(collector.New("", addr, collector.Options{MaxPlayerSeries: 2, PlayerOrder: tt.order}))
+			metrics := testGather(t, registry)
+
+			testAssertValue(t, metrics, "players_truncated", 2)
+			testAssertValue(t, metrics, "player_count", 4)
+
+			var gauges []expectGauge
+			for _, name := range tt.want {
+				gauges = append(gauges, expectGauge{value: 1, labels: map[string]string{"player_name": name}})
+			}
+			testAssertGauge(t, metrics, "player_info", gauges...)
+		})
+	}
+}
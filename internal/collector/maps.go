@@ -0,0 +1,68 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/rumblefrog/go-a2s"
+)
+
+// mapTracker follows the map the server is running, and the time spent on each map.
+type mapTracker struct {
+	current string
+	// start is when the current map was first seen. For the map running when the exporter started, this is later than
+	// the actual start of the map.
+	start    time.Time
+	lastTime time.Time
+	changes  float64
+	seconds  map[string]float64
+}
+
+// update records the map of a query, and reports whether it changed since the previous successful query. The time
+// since the previous query counts towards the previous map. The time while the server is down does not count.
+func (t *mapTracker) update(now time.Time, serverInfo *a2s.ServerInfo) bool {
+	if serverInfo == nil {
+		t.lastTime = time.Time{}
+		return false
+	}
+
+	if t.seconds == nil {
+		t.seconds = make(map[string]float64)
+	}
+
+	if t.current != "" && !t.lastTime.IsZero() {
+		if gap := now.Sub(t.lastTime); gap > 0 && gap <= maxQueryGap {
+			t.seconds[t.current] += gap.Seconds()
+		}
+	}
+	t.lastTime = now
+
+	changed := t.current != "" && serverInfo.Map != t.current
+	if changed {
+		t.changes++
+	}
+	if changed || t.current == "" {
+		t.current = serverInfo.Map
+		t.start = now
+	}
+
+	// Make the series of the current map appear as soon as it is seen.
+	if _, ok := t.seconds[t.current]; !ok {
+		t.seconds[t.current] = 0
+	}
+
+	return changed
+}
+
+func (c *Collector) collectMaps(add adder) {
+	t := &c.maps
+
+	add("map_changes_total", t.changes)
+
+	if !t.start.IsZero() {
+		add("current_map_start_timestamp_seconds", float64(t.start.UnixNano())/1e9)
+	}
+
+	for name, seconds := range t.seconds {
+		add("map_seconds_total", seconds, name)
+	}
+}
@@ -0,0 +1,80 @@
+package collector_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	io_prometheus_client "github.com/prometheus/client_model/go"
+	"github.com/rumblefrog/go-a2s"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+	"github.com/armsnyder/a2s-exporter/internal/testserver"
+)
+
+func TestCollector_Maps(t *testing.T) {
+	srv := &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo", Map: "de_dust2"},
+	}
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector.New("", testServe(t, srv), collector.Options{ExcludePlayerMetrics: true}))
+
+	metrics := testGather(t, registry)
+	testAssertValue(t, metrics, "map_changes_total", 0)
+	testAssertMapSeries(t, metrics, "de_dust2")
+	start := testMetricValue(t, metrics, "current_map_start_timestamp_seconds")
+	if now := float64(time.Now().Unix()); start < now-60 || start > now+60 {
+		t.Errorf("wanted the map start to be close to %v, got %v", now, start)
+	}
+
+	srv.Update(func() { srv.ServerInfo.Map = "de_inferno" })
+
+	metrics = testGather(t, registry)
+	testAssertValue(t, metrics, "map_changes_total", 1)
+	testAssertMapSeries(t, metrics, "de_dust2", "de_inferno")
+	if got := testMetricValue(t, metrics, "current_map_start_timestamp_seconds"); got < start {
+		t.Errorf("wanted the map start to move forward from %v, got %v", start, got)
+	}
+}
+
+// testAssertMapSeries checks that map_seconds_total has a series for each of the maps.
+func testAssertMapSeries(t *testing.T, metricFamilies []*io_prometheus_client.MetricFamily, maps ...string) {
+	t.Helper()
+
+	got := make(map[string]bool)
+	for _, family := range metricFamilies {
+		if family.GetName() != "map_seconds_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "map" {
+					got[label.GetValue()] = true
+				}
+			}
+		}
+	}
+
+	if len(got) != len(maps) {
+		t.Errorf("wanted map_seconds_total series for %v, got %v", maps, got)
+	}
+	for _, name := range maps {
+		if !got[name] {
+			t.Errorf("wanted a map_seconds_total series for %s", name)
+		}
+	}
+}
+
+// testMetricValue returns the value of a gauge which has a single series.
+func testMetricValue(t *testing.T, metricFamilies []*io_prometheus_client.MetricFamily, name string) float64 {
+	t.Helper()
+
+	for _, family := range metricFamilies {
+		if family.GetName() == name && len(family.GetMetric()) == 1 {
+			return family.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+
+	t.Fatalf("metric %s not found", name)
+	return 0
+}
@@ -0,0 +1,88 @@
+package collector
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rumblefrog/go-a2s"
+)
+
+var (
+	// richTextPattern matches the Unity rich text tags used by games such as Valheim and Rust, for example
+	// <color=#ff0000> and </b>.
+	richTextPattern = regexp.MustCompile(`(?i)</?(?:b|i|u|s|color|size|material|quad|sprite|font|mark|alpha|align|noparse)(?:=[^<>]*)?>`)
+
+	// colorCodePattern matches Quake-style color codes, for example ^1.
+	colorCodePattern = regexp.MustCompile(`\^[0-9]`)
+)
+
+// normalizePlayerName cleans up a player name for use as a label value: markup and control characters are removed,
+// invalid UTF-8 is replaced, surrounding whitespace is trimmed, and the name is truncated to maxLength characters if
+// maxLength is positive.
+func normalizePlayerName(name string, maxLength int) string {
+	name = strings.ToValidUTF8(name, string(utf8.RuneError))
+	name = richTextPattern.ReplaceAllString(name, "")
+	name = colorCodePattern.ReplaceAllString(name, "")
+
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+
+	name = strings.TrimSpace(name)
+
+	if maxLength > 0 && utf8.RuneCountInString(name) > maxLength {
+		name = strings.TrimSpace(string([]rune(name)[:maxLength]))
+	}
+
+	return name
+}
+
+// normalizePlayers normalizes the player names in place if enabled. Invalid UTF-8 is always replaced, since it is not a
+// valid label value. It is applied to the query results before they are used for anything else.
+func (c *Collector) normalizePlayers(playerInfo *a2s.PlayerInfo) {
+	if playerInfo == nil {
+		return
+	}
+
+	for _, player := range playerInfo.Players {
+		if c.normalizePlayerNames {
+			player.Name = normalizePlayerName(player.Name, c.playerNameMaxLength)
+		} else {
+			player.Name = strings.ToValidUTF8(player.Name, string(utf8.RuneError))
+		}
+	}
+}
+
+// excludePlayers removes the players whose name matches one of the exclude patterns, so that they are left out of
+// every output other than the player list classes.
+func (c *Collector) excludePlayers(playerInfo *a2s.PlayerInfo) {
+	if playerInfo == nil {
+		return
+	}
+
+	kept := playerInfo.Players[:0]
+
+	for _, player := range playerInfo.Players {
+		if c.excludedPlayerName(player.Name) {
+			continue
+		}
+
+		kept = append(kept, player)
+	}
+
+	playerInfo.Players = kept
+}
+
+func (c *Collector) excludedPlayerName(name string) bool {
+	for _, pattern := range c.excludePlayerNames {
+		if pattern.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package collector_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rumblefrog/go-a2s"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+	"github.com/armsnyder/a2s-exporter/internal/testserver"
+)
+
+func TestCollector_NormalizePlayerNames(t *testing.T) {
+	addr := testServe(t, &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo"},
+		PlayerInfo: &a2s.PlayerInfo{Count: 7, Players: []*a2s.Player{
+			{Name: "<color=#ff0000>Viking</color> <b>Bob</b>", Duration: 1},
+			{Name: "^1Red^7Fragger", Duration: 2},
+			{Name: "  tab\there\x07  ", Duration: 3},
+			{Name: "bad\xffutf8", Duration: 4},
+			{Name: "a very long player name indeed", Duration: 5},
+			{Name: "Unknown", Duration: 6},
+			{Name: "[BOT] Steve", Duration: 7},
+		}},
+	})
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector.New("", addr, collector.Options{
+		NormalizePlayerNames: true,
+		PlayerNameMaxLength:  12,
+		ExcludePlayerNames: []*regexp.Regexp{
+			regexp.MustCompile(`^Unknown$`),
+			regexp.MustCompile(`^\[BOT\]`),
+		},
+	}))
+	metrics := testGather(t, registry)
+
+	testAssertGauge(t, metrics, "player_duration",
+		expectGauge{value: 1, labels: map[string]string{"player_name": "Viking Bob"}},
+		expectGauge{value: 2, labels: map[string]string{"player_name": "RedFragger"}},
+		expectGauge{value: 3, labels: map[string]string{"player_name": "tabhere"}},
+		expectGauge{value: 4, labels: map[string]string{"player_name": "bad�utf8"}},
+		expectGauge{value: 5, labels: map[string]string{"player_name": "a very long"}},
+	)
+}
+
+func TestCollector_InvalidUTF8PlayerName(t *testing.T) {
+	addr := testServe(t, &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo"},
+		PlayerInfo: &a2s.PlayerInfo{Count: 1, Players: []*a2s.Player{{Name: "a\xff", Duration: 1}}},
+	})
+
+	// Invalid UTF-8 is replaced even without normalization, since it would fail the scrape.
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector.New("", addr, collector.Options{}))
+	metrics := testGather(t, registry)
+
+	testAssertGauge(t, metrics, "player_duration", expectGauge{value: 1, labels: map[string]string{"player_name": "a�"}})
+}
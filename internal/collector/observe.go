@@ -0,0 +1,101 @@
+package collector
+
+import (
+	"github.com/rumblefrog/go-a2s"
+
+	"github.com/armsnyder/a2s-exporter/internal/events"
+)
+
+// observe updates the state which is tracked between queries, and emits events for the changes since the previous
+// query. It must be called with the lock held.
+func (c *Collector) observe(prev, cur Status) {
+	emit := func(event events.Event) {
+		if c.events == nil {
+			return
+		}
+		event.Time = cur.Time
+		event.Target = c.addr
+		if c.lastServerInfo != nil {
+			event.ServerName = c.lastServerInfo.Name
+		}
+		c.events.Emit(event)
+	}
+
+	// The first query is a baseline, so transitions are only reported after it.
+	if !prev.Time.IsZero() {
+		if prev.ServerUp && !cur.ServerUp {
+			emit(events.Event{Type: events.ServerDown})
+		}
+		if !prev.ServerUp && cur.ServerUp {
+			emit(events.Event{Type: events.ServerUp})
+		}
+	}
+
+	// Players are followed between queries by their sessions, which the rounds are tracked by.
+	var changes sessionChanges
+	var players []*a2s.Player
+	var roundPlayers []roundEntry
+	if cur.PlayerInfo != nil {
+		players = cur.PlayerInfo.Players
+		changes = c.sessions.update(cur.Time, players)
+		roundPlayers = roundEntries(c.sessions.active, players)
+	}
+
+	mapChanged := c.maps.update(cur.Time, cur.ServerInfo)
+	// Players reconnecting on a map change is a new round rather than a restart.
+	namesOmitted := c.playerNameMode == PlayerNameOmitted
+	reconnected := roundPlayers != nil && !mapChanged && c.rounds.initialized && c.rounds.durationsReset(roundPlayers, namesOmitted)
+	c.rounds.update(mapChanged, roundPlayers, namesOmitted)
+
+	if cur.ServerInfo != nil {
+		last := c.lastServerInfo
+		c.lastServerInfo = cur.ServerInfo
+
+		if c.restarts.update(cur.Time, prev.ServerUp, last, cur.ServerInfo, players, reconnected) {
+			emit(events.Event{Type: events.ServerRestart})
+		}
+
+		if last != nil && last.Map != cur.ServerInfo.Map {
+			emit(events.Event{
+				Type:        events.MapChange,
+				Map:         cur.ServerInfo.Map,
+				PreviousMap: last.Map,
+			})
+		}
+
+		if last != nil && !isFull(last) && isFull(cur.ServerInfo) {
+			emit(events.Event{
+				Type:       events.ServerFull,
+				Players:    int(cur.ServerInfo.Players),
+				MaxPlayers: int(cur.ServerInfo.MaxPlayers),
+			})
+		}
+	} else {
+		c.restarts.down(cur.Time)
+	}
+
+	c.stats.update(cur.Time, cur.ServerInfo, cur.PlayerInfo)
+
+	for _, session := range changes.left {
+		connected := session.connected
+		emit(events.Event{
+			Type:           events.PlayerLeave,
+			Player:         session.name,
+			ConnectedAt:    &connected,
+			SessionSeconds: session.lastSeen.Sub(session.connected).Seconds(),
+		})
+	}
+
+	for _, session := range changes.joined {
+		connected := session.connected
+		emit(events.Event{
+			Type:        events.PlayerJoin,
+			Player:      session.name,
+			ConnectedAt: &connected,
+		})
+	}
+}
+
+func isFull(serverInfo *a2s.ServerInfo) bool {
+	return serverInfo.MaxPlayers > 0 && serverInfo.Players >= serverInfo.MaxPlayers
+}
@@ -0,0 +1,113 @@
+package collector_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/rumblefrog/go-a2s"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+	"github.com/armsnyder/a2s-exporter/internal/events"
+	"github.com/armsnyder/a2s-exporter/internal/testserver"
+)
+
+func TestCollector_Events(t *testing.T) {
+	srv := &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo", Map: "de_dust2", Players: 1, MaxPlayers: 2},
+		PlayerInfo: &a2s.PlayerInfo{Count: 1, Players: []*a2s.Player{
+			{Name: "jon", Duration: 600},
+		}},
+	}
+	addr := testServe(t, srv)
+	sink := &testSink{}
+	c := collector.New("", addr, collector.Options{Events: sink})
+	defer c.Close()
+
+	// Baseline.
+	c.Refresh()
+	if got := sink.types(); len(got) != 0 {
+		t.Errorf("expected no events for the first query but got %v", got)
+	}
+
+	// jon leaves, alice joins, the map changes and the server fills up.
+	srv.Update(func() {
+		srv.ServerInfo = &a2s.ServerInfo{Name: "foo", Map: "de_inferno", Players: 2, MaxPlayers: 2}
+		srv.PlayerInfo = &a2s.PlayerInfo{Count: 1, Players: []*a2s.Player{
+			{Name: "alice", Duration: 5},
+		}}
+	})
+	c.Refresh()
+
+	testAssertEventTypes(t, sink.types(), events.MapChange, events.ServerFull, events.PlayerLeave, events.PlayerJoin)
+
+	for _, event := range sink.take() {
+		if event.Target != addr || event.ServerName != "foo" {
+			t.Errorf("unexpected target or server name in event %+v", event)
+		}
+		switch event.Type {
+		case events.MapChange:
+			if event.Map != "de_inferno" || event.PreviousMap != "de_dust2" {
+				t.Errorf("unexpected map change %+v", event)
+			}
+		case events.PlayerLeave:
+			if event.Player != "jon" || event.SessionSeconds < 600 || event.ConnectedAt == nil {
+				t.Errorf("unexpected player leave %+v", event)
+			}
+		case events.PlayerJoin:
+			if event.Player != "alice" {
+				t.Errorf("unexpected player join %+v", event)
+			}
+		case events.ServerFull:
+			if event.Players != 2 || event.MaxPlayers != 2 {
+				t.Errorf("unexpected server full %+v", event)
+			}
+		}
+	}
+}
+
+// testSink records events.
+type testSink struct {
+	mu     sync.Mutex
+	events []events.Event
+}
+
+func (s *testSink) Emit(event events.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+}
+
+// take returns and clears the recorded events.
+func (s *testSink) take() []events.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	taken := s.events
+	s.events = nil
+	return taken
+}
+
+func (s *testSink) types() []events.Type {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	types := make([]events.Type, 0, len(s.events))
+	for _, event := range s.events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func testAssertEventTypes(t *testing.T, got []events.Type, want ...events.Type) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Errorf("wanted events %v, got %v", want, got)
+		return
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("wanted events %v, got %v", want, got)
+			return
+		}
+	}
+}
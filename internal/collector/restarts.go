@@ -0,0 +1,118 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/rumblefrog/go-a2s"
+)
+
+// restartTracker detects restarts of the game server, and estimates when it started.
+type restartTracker struct {
+	// start is the estimated start of the server. Until a restart is seen, it is a lower bound on the uptime: the
+	// server has been running at least as long as its longest connected player.
+	start          time.Time
+	restarts       float64
+	versionChanges float64
+	// lastUp is the time of the last successful server info query, and downSince is the time of the first failed query
+	// after it, or zero if the server is up.
+	lastUp    time.Time
+	downSince time.Time
+	// failures is the number of consecutive failed server info queries.
+	failures int
+}
+
+// minRestartDowntime and minRestartFailures are how long, and for how many consecutive queries, an empty server must
+// fail to answer for it coming back to count as a restart. A shorter outage is as likely to be lost packets, and with
+// a long scrape interval, a single lost packet can span minRestartDowntime.
+const (
+	minRestartDowntime = 2 * time.Minute
+	minRestartFailures = 2
+)
+
+// update compares a successful server info query with the previous one, and reports whether the server restarted in
+// between. A restart is a version change, a Steam ID change, the server coming back after being down, or all players
+// reconnecting without a map change. last is nil on the first query, and players is nil if the player query failed.
+func (t *restartTracker) update(now time.Time, prevUp bool, last, cur *a2s.ServerInfo, players []*a2s.Player, reconnected bool) bool {
+	restarted := false
+
+	if last != nil {
+		if last.Version != "" && cur.Version != "" && last.Version != cur.Version {
+			t.versionChanges++
+			restarted = true
+		}
+
+		if steamID(last) != 0 && steamID(cur) != 0 && steamID(last) != steamID(cur) {
+			restarted = true
+		}
+
+		if reconnected || (!prevUp && t.cameBack(now, players)) {
+			restarted = true
+		}
+	}
+
+	// Every player connected after the server started, so the longest connected player bounds the start.
+	start := now
+	for _, player := range players {
+		if connected := connectedAt(now, player); connected.Before(start) {
+			start = connected
+		}
+	}
+
+	if restarted {
+		t.restarts++
+		t.start = start
+	} else if t.start.IsZero() || start.Before(t.start) {
+		t.start = start
+	}
+
+	t.lastUp = now
+	t.downSince = time.Time{}
+	t.failures = 0
+
+	return restarted
+}
+
+// down records a failed server info query.
+func (t *restartTracker) down(now time.Time) {
+	if t.downSince.IsZero() {
+		t.downSince = now
+	}
+	t.failures++
+}
+
+// cameBack reports whether the server answering again after failing to answer was a restart rather than lost packets.
+// If players are connected, it was a restart if all of them connected since the last successful query. An empty server
+// must have been down for at least minRestartDowntime and minRestartFailures queries.
+func (t *restartTracker) cameBack(now time.Time, players []*a2s.Player) bool {
+	if len(players) > 0 {
+		if t.lastUp.IsZero() {
+			return false
+		}
+		for _, player := range players {
+			if connectedAt(now, player).Before(t.lastUp) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return t.failures >= minRestartFailures && !t.downSince.IsZero() && now.Sub(t.downSince) >= minRestartDowntime
+}
+
+func steamID(serverInfo *a2s.ServerInfo) uint64 {
+	if serverInfo.ExtendedServerInfo == nil {
+		return 0
+	}
+	return serverInfo.ExtendedServerInfo.SteamID
+}
+
+func (c *Collector) collectRestarts(add adder) {
+	t := &c.restarts
+
+	add("server_restarts_total", t.restarts)
+	add("server_version_changes_total", t.versionChanges)
+
+	if !t.start.IsZero() {
+		add("server_start_timestamp_seconds", float64(t.start.UnixNano())/1e9)
+	}
+}
@@ -0,0 +1,240 @@
+package collector_test
+
+import (
+	"encoding/json"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	io_prometheus_client "github.com/prometheus/client_model/go"
+	"github.com/rumblefrog/go-a2s"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+	"github.com/armsnyder/a2s-exporter/internal/events"
+	"github.com/armsnyder/a2s-exporter/internal/testserver"
+)
+
+func TestCollector_Restarts(t *testing.T) {
+	srv := &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo", Map: "de_dust2", Version: "1.0", ExtendedServerInfo: &a2s.ExtendedServerInfo{SteamID: 1}},
+		PlayerInfo: &a2s.PlayerInfo{Count: 2, Players: []*a2s.Player{
+			{Name: "jon", Duration: 3600},
+			{Name: "alice", Duration: 60},
+		}},
+	}
+	sink := &testSink{}
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector.New("", testServe(t, srv), collector.Options{Events: sink}))
+
+	// The start is estimated from the longest connected player.
+	metrics := testGather(t, registry)
+	testAssertValue(t, metrics, "server_restarts_total", 0)
+	testAssertValue(t, metrics, "server_version_changes_total", 0)
+	testAssertStart(t, testMetricValue(t, metrics, "server_start_timestamp_seconds"), time.Hour)
+
+	// A new version.
+	srv.Update(func() {
+		srv.ServerInfo.Version = "1.1"
+		srv.PlayerInfo = &a2s.PlayerInfo{}
+	})
+	metrics = testGather(t, registry)
+	testAssertValue(t, metrics, "server_restarts_total", 1)
+	testAssertValue(t, metrics, "server_version_changes_total", 1)
+	testAssertStart(t, testMetricValue(t, metrics, "server_start_timestamp_seconds"), 0)
+
+	// A new Steam ID.
+	srv.Update(func() {
+		srv.ServerInfo.ExtendedServerInfo.SteamID = 2
+		srv.PlayerInfo = &a2s.PlayerInfo{Count: 2, Players: []*a2s.Player{
+			{Name: "jon", Duration: 120},
+			{Name: "alice", Duration: 60},
+		}}
+	})
+	metrics = testGather(t, registry)
+	testAssertValue(t, metrics, "server_restarts_total", 2)
+	testAssertValue(t, metrics, "server_version_changes_total", 1)
+
+	// Everybody reconnects on the same map.
+	srv.Update(func() {
+		srv.PlayerInfo = &a2s.PlayerInfo{Count: 2, Players: []*a2s.Player{
+			{Name: "jon", Duration: 10},
+			{Name: "alice", Duration: 5},
+		}}
+	})
+	metrics = testGather(t, registry)
+	testAssertValue(t, metrics, "server_restarts_total", 3)
+	testAssertStart(t, testMetricValue(t, metrics, "server_start_timestamp_seconds"), 10*time.Second)
+
+	// Everybody reconnects on a new map, which is a new round rather than a restart.
+	srv.Update(func() {
+		srv.ServerInfo.Map = "de_inferno"
+		srv.PlayerInfo = &a2s.PlayerInfo{Count: 2, Players: []*a2s.Player{
+			{Name: "jon", Duration: 2},
+			{Name: "alice", Duration: 1},
+		}}
+	})
+	metrics = testGather(t, registry)
+	testAssertValue(t, metrics, "server_restarts_total", 3)
+
+	var restarts int
+	for _, eventType := range sink.types() {
+		if eventType == events.ServerRestart {
+			restarts++
+		}
+	}
+	if restarts != 3 {
+		t.Errorf("wanted 3 server_restart events, got %d", restarts)
+	}
+}
+
+func TestCollector_Restarts_ServerDown(t *testing.T) {
+	srv := &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo", Map: "de_dust2"},
+		PlayerInfo: &a2s.PlayerInfo{Count: 2, Players: []*a2s.Player{
+			{Name: "jon", Duration: 3600},
+			{Name: "alice", Duration: 60},
+		}},
+	}
+	addr, conn := testServeDropping(t, srv)
+	sink := &testSink{}
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector.New("", addr, collector.Options{
+		Events:        sink,
+		ClientOptions: []func(*a2s.Client) error{a2s.TimeoutOption(100 * time.Millisecond)},
+	}))
+
+	assertDownThenUp := func(restarts float64) {
+		t.Helper()
+		conn.drop.Store(true)
+		testAssertValue(t, testGather(t, registry), "server_up", 0)
+		conn.drop.Store(false)
+		testAssertValue(t, testGather(t, registry), "server_restarts_total", restarts)
+	}
+
+	testAssertValue(t, testGather(t, registry), "server_restarts_total", 0)
+
+	// A lost packet is not a restart, since the players stayed connected.
+	assertDownThenUp(0)
+
+	// An empty server which answers again soon is not a restart either.
+	srv.Update(func() { srv.PlayerInfo = &a2s.PlayerInfo{} })
+	testAssertValue(t, testGather(t, registry), "server_restarts_total", 0)
+	assertDownThenUp(0)
+
+	// The server comes back with only players who connected while it was down.
+	time.Sleep(time.Second)
+	srv.Update(func() {
+		srv.PlayerInfo = &a2s.PlayerInfo{Count: 1, Players: []*a2s.Player{{Name: "bob", Duration: 0.5}}}
+	})
+	assertDownThenUp(1)
+
+	var restarts int
+	for _, eventType := range sink.types() {
+		if eventType == events.ServerRestart {
+			restarts++
+		}
+	}
+	if restarts != 1 {
+		t.Errorf("wanted 1 server_restart event, got %d", restarts)
+	}
+}
+
+func TestCollector_Restarts_LongScrapeInterval(t *testing.T) {
+	srv := &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo", Map: "de_dust2"},
+		PlayerInfo: &a2s.PlayerInfo{},
+	}
+	addr, conn := testServeDropping(t, srv)
+	opts := collector.Options{ClientOptions: []func(*a2s.Client) error{a2s.TimeoutOption(100 * time.Millisecond)}}
+
+	// gatherAfter queries an empty server, fails the given number of queries, and gathers once the server answers
+	// again, 5 minutes after the first failure.
+	gatherAfter := func(failures int) []*io_prometheus_client.MetricFamily {
+		t.Helper()
+
+		before := collector.New("", addr, opts)
+		_ = testGather(t, testRegister(before))
+		conn.drop.Store(true)
+		for i := 0; i < failures; i++ {
+			_ = testGather(t, testRegister(before))
+		}
+		conn.drop.Store(false)
+
+		data, err := before.SaveState()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var state map[string]any
+		if err := json.Unmarshal(data, &state); err != nil {
+			t.Fatal(err)
+		}
+		restarts := state["restarts"].(map[string]any)
+		restarts["down_since"] = time.Now().Add(-5 * time.Minute)
+		restarts["last_up"] = time.Now().Add(-6 * time.Minute)
+		if data, err = json.Marshal(state); err != nil {
+			t.Fatal(err)
+		}
+
+		after := collector.New("", addr, opts)
+		if err := after.RestoreState(data); err != nil {
+			t.Fatal(err)
+		}
+		return testGather(t, testRegister(after))
+	}
+
+	// With a long scrape interval, a single lost packet can span the minimum downtime.
+	testAssertValue(t, gatherAfter(1), "server_restarts_total", 0)
+	testAssertValue(t, gatherAfter(2), "server_restarts_total", 1)
+}
+
+// testRegister registers the collector with a new registry.
+func testRegister(c prometheus.Collector) *prometheus.Registry {
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(c)
+	return registry
+}
+
+// droppingConn drops the requests it receives while drop is set, so that queries time out.
+type droppingConn struct {
+	net.PacketConn
+	drop atomic.Bool
+}
+
+func (c *droppingConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	for {
+		n, addr, err := c.PacketConn.ReadFrom(p)
+		if err != nil || !c.drop.Load() {
+			return n, addr, err
+		}
+	}
+}
+
+// testServeDropping runs a test A2S server whose requests can be dropped, and returns its address.
+func testServeDropping(t *testing.T, srv *testserver.TestServer) (string, *droppingConn) {
+	t.Helper()
+
+	udpConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = udpConn.Close() })
+
+	conn := &droppingConn{PacketConn: udpConn}
+	go func() {
+		_ = srv.Serve(conn)
+	}()
+
+	return udpConn.LocalAddr().String(), conn
+}
+
+// testAssertStart checks that a start timestamp is about the given time ago.
+func testAssertStart(t *testing.T, got float64, ago time.Duration) {
+	t.Helper()
+
+	want := float64(time.Now().Add(-ago).Unix())
+	if got < want-60 || got > want+60 {
+		t.Errorf("wanted a start timestamp close to %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,142 @@
+package collector
+
+import (
+	"github.com/rumblefrog/go-a2s"
+)
+
+// minRoundPlayers is the number of players who must be in two consecutive player lists for their scores or durations
+// to indicate a new round. With fewer players, a single player reconnecting would look like a new round.
+const minRoundPlayers = 2
+
+// roundPlayer is the score of a player at the previous query, and the score accumulated across rounds.
+type roundPlayer struct {
+	name  string
+	score float64
+	total float64
+}
+
+// roundEntry is a player in the player list, identified across queries by the player's session.
+type roundEntry struct {
+	id    uint64
+	name  string
+	score float64
+}
+
+// roundTracker detects round boundaries, so that scores which reset every round can be accumulated into a counter.
+type roundTracker struct {
+	initialized bool
+	// pending is set when a round change was detected without a player list, so that the next player list starts a
+	// new round.
+	pending bool
+	// players are by session id.
+	players map[uint64]roundPlayer
+	changes float64
+}
+
+// roundEntries pairs the players with their sessions, which are in the same order.
+func roundEntries(sessions []playerSession, players []*a2s.Player) []roundEntry {
+	entries := make([]roundEntry, 0, len(players))
+	for i, player := range players {
+		entries = append(entries, roundEntry{id: sessions[i].id, name: player.Name, score: float64(player.Score)})
+	}
+	return entries
+}
+
+// update detects whether a new round started since the previous query, and accumulates the scores of the players. A
+// round change is a map change, most of the players' scores resetting to zero, or all of the players' connection
+// durations resetting. The players are nil if the player query failed.
+func (t *roundTracker) update(mapChanged bool, players []roundEntry, namesOmitted bool) bool {
+	changed := mapChanged
+
+	if players != nil {
+		if !changed && t.initialized && (t.scoresReset(players) || t.durationsReset(players, namesOmitted)) {
+			changed = true
+		}
+		t.accumulate(players, changed || t.pending)
+		t.pending = false
+		t.initialized = true
+	} else if changed {
+		t.pending = true
+	}
+
+	if changed {
+		t.changes++
+	}
+
+	return changed
+}
+
+// scoresReset reports whether at least half of the players who had a score at the previous query now have none.
+func (t *roundTracker) scoresReset(players []roundEntry) bool {
+	var scored, reset int
+	for _, player := range players {
+		prev, ok := t.players[player.id]
+		if !ok || prev.score == 0 {
+			continue
+		}
+		scored++
+		if player.score == 0 {
+			reset++
+		}
+	}
+	return scored >= minRoundPlayers && reset*2 >= scored
+}
+
+// durationsReset reports whether none of the players at the previous query is still connected, meaning that everyone
+// reconnected. Unless names are omitted, enough of the players must be back under the same names, so that the players
+// being replaced by others is not taken for a new round.
+func (t *roundTracker) durationsReset(players []roundEntry, namesOmitted bool) bool {
+	if len(t.players) < minRoundPlayers || len(players) < minRoundPlayers {
+		return false
+	}
+
+	prevNames := make(map[string]int, len(t.players))
+	for _, prev := range t.players {
+		prevNames[prev.name]++
+	}
+
+	var returned int
+	for _, player := range players {
+		if _, ok := t.players[player.id]; ok {
+			return false
+		}
+		if prevNames[player.name] > 0 {
+			prevNames[player.name]--
+			returned++
+		}
+	}
+	return namesOmitted || returned >= minRoundPlayers
+}
+
+// accumulate adds the score gained since the previous query to each player's total. In a new round, the whole score
+// was gained since the previous query. A score which went down within a round is not subtracted, since the total is a
+// counter. Players who left are forgotten.
+func (t *roundTracker) accumulate(players []roundEntry, newRound bool) {
+	next := make(map[uint64]roundPlayer, len(players))
+
+	for _, player := range players {
+		score := player.score
+		prev, ok := t.players[player.id]
+
+		total := prev.total
+		switch {
+		case !ok, newRound:
+			total += score
+		case score > prev.score:
+			total += score - prev.score
+		}
+
+		next[player.id] = roundPlayer{name: player.name, score: score, total: total}
+	}
+
+	t.players = next
+}
+
+// scoreTotals returns a copy of the accumulated score of each session in the latest player list.
+func (t *roundTracker) scoreTotals() map[uint64]float64 {
+	totals := make(map[uint64]float64, len(t.players))
+	for id, player := range t.players {
+		totals[id] = player.total
+	}
+	return totals
+}
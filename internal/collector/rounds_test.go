@@ -0,0 +1,121 @@
+package collector_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	io_prometheus_client "github.com/prometheus/client_model/go"
+	"github.com/rumblefrog/go-a2s"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+	"github.com/armsnyder/a2s-exporter/internal/testserver"
+)
+
+func TestCollector_Rounds(t *testing.T) {
+	players := func(scores ...uint32) *a2s.PlayerInfo {
+		names := []string{"jon", "alice", "bob"}
+		playerInfo := &a2s.PlayerInfo{Count: uint8(len(scores))}
+		for i, score := range scores {
+			playerInfo.Players = append(playerInfo.Players, &a2s.Player{Name: names[i], Score: score, Duration: 100})
+		}
+		return playerInfo
+	}
+
+	srv := &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo", Map: "de_dust2"},
+		PlayerInfo: players(5, 3, 0),
+	}
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector.New("", testServe(t, srv), collector.Options{}))
+
+	assertTotals := func(rounds float64, jon, alice, bob float64) {
+		t.Helper()
+		metrics := testGather(t, registry)
+		testAssertValue(t, metrics, "round_changes_total", rounds)
+		testAssertPlayerCounters(t, metrics, "player_score_total", map[string]float64{"jon": jon, "alice": alice, "bob": bob})
+	}
+
+	// The counters start at the current scores.
+	assertTotals(0, 5, 3, 0)
+
+	// Scores go up within the round. A score going down is not subtracted.
+	srv.Update(func() { srv.PlayerInfo = players(8, 2, 1) })
+	assertTotals(0, 8, 3, 1)
+
+	// Most scores reset to zero, so a new round started.
+	srv.Update(func() { srv.PlayerInfo = players(0, 0, 2) })
+	assertTotals(1, 8, 3, 3)
+
+	srv.Update(func() { srv.PlayerInfo = players(4, 1, 3) })
+	assertTotals(1, 12, 4, 4)
+
+	// A map change starts a new round, and the scores in it count in full.
+	srv.Update(func() {
+		srv.ServerInfo.Map = "de_inferno"
+		srv.PlayerInfo = players(2, 1, 1)
+	})
+	assertTotals(2, 14, 5, 5)
+}
+
+// testAssertPlayerCounters checks the value of a per-player counter for each player name.
+func testAssertPlayerCounters(t *testing.T, metricFamilies []*io_prometheus_client.MetricFamily, name string, want map[string]float64) {
+	t.Helper()
+
+	got := make(map[string]float64)
+	for _, family := range metricFamilies {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "player_name" {
+					got[label.GetValue()] = metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("metric %s: wanted %v, got %v", name, want, got)
+	}
+}
+
+func TestCollector_Rounds_OmittedNames(t *testing.T) {
+	players := func(duration float32, scores ...uint32) *a2s.PlayerInfo {
+		playerInfo := &a2s.PlayerInfo{Count: uint8(len(scores))}
+		for i, score := range scores {
+			// Players are told apart by when they connected, so they connected at different times.
+			playerInfo.Players = append(playerInfo.Players, &a2s.Player{Score: score, Duration: duration + float32(i*60)})
+		}
+		return playerInfo
+	}
+
+	srv := &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo", Map: "de_dust2"},
+		PlayerInfo: players(100, 5, 3, 0),
+	}
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector.New("", testServe(t, srv), collector.Options{PlayerNameMode: collector.PlayerNameOmitted}))
+
+	testAssertValue(t, testGather(t, registry), "round_changes_total", 0)
+
+	srv.Update(func() { srv.PlayerInfo = players(100, 8, 4, 1) })
+	testAssertValue(t, testGather(t, registry), "round_changes_total", 0)
+
+	// Latency moves the derived connection times by a few seconds, which is not everyone reconnecting.
+	srv.Update(func() { srv.PlayerInfo = players(95, 8, 4, 1) })
+	metrics := testGather(t, registry)
+	testAssertValue(t, metrics, "round_changes_total", 0)
+	testAssertValue(t, metrics, "server_restarts_total", 0)
+
+	// Most scores reset to zero, so a new round started.
+	srv.Update(func() { srv.PlayerInfo = players(100, 0, 0, 2) })
+	testAssertValue(t, testGather(t, registry), "round_changes_total", 1)
+
+	// Everyone reconnected, so a new round started, and the server restarted.
+	srv.Update(func() { srv.PlayerInfo = players(5, 0, 0, 0) })
+	metrics = testGather(t, registry)
+	testAssertValue(t, metrics, "round_changes_total", 2)
+	testAssertValue(t, metrics, "server_restarts_total", 1)
+}
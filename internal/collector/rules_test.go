@@ -0,0 +1,44 @@
+package collector_test
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rumblefrog/go-a2s"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+	"github.com/armsnyder/a2s-exporter/internal/testserver"
+)
+
+func TestCollector_RuleMetrics(t *testing.T) {
+	srv := &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo"},
+		Rules: &a2s.RulesInfo{Count: 4, Rules: map[string]string{
+			"mp_timelimit": "30",
+			"sv_cheats":    "false",
+			"sv_tags":      "casual,secure",
+			"fps":          " 59.9 ",
+		}},
+	}
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector.New("", testServe(t, srv), collector.Options{
+		ExcludePlayerMetrics: true,
+		QueryRules:           true,
+		RuleMetrics: []collector.RuleMetric{
+			{Rule: "mp_timelimit"},
+			{Rule: "sv_cheats"},
+			{Rule: "sv_tags"},
+			{Rule: "fps", Name: "server_fps"},
+			{Rule: "missing"},
+		},
+	}))
+	metrics := testGather(t, registry)
+
+	testAssertValue(t, metrics, "rules_up", 1)
+	testAssertValue(t, metrics, "server_rules", 4)
+	testAssertGauge(t, metrics, "server_rule",
+		expectGauge{value: 30, labels: map[string]string{"server_name": "foo", "rule": "mp_timelimit"}},
+		expectGauge{value: 0, labels: map[string]string{"server_name": "foo", "rule": "sv_cheats"}},
+		expectGauge{value: 59.9, labels: map[string]string{"server_name": "foo", "rule": "server_fps"}},
+	)
+}
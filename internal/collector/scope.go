@@ -0,0 +1,88 @@
+package collector
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rumblefrog/go-a2s"
+)
+
+// Scope selects which queries are made, and which metrics are collected. The server info is always queried, since
+// every other metric is labeled with it.
+type Scope struct {
+	// Players queries the player list, for the player_* metrics.
+	Players bool
+	// Rules queries the server rules, for the rule metrics.
+	Rules bool
+}
+
+// DefaultScope returns the Scope selected by the Options.
+func (c *Collector) DefaultScope() Scope {
+	return Scope{
+		Players: !c.excludePlayerMetrics,
+		Rules:   c.queryRules,
+	}
+}
+
+// Scoped returns a prometheus.Collector which collects the metrics of this Collector within the scope, for example
+// for a scrape which asked for the server info alone.
+func (c *Collector) Scoped(scope Scope) prometheus.Collector {
+	return &scopedCollector{c: c, scope: scope}
+}
+
+type scopedCollector struct {
+	c     *Collector
+	scope Scope
+}
+
+func (s *scopedCollector) Describe(descs chan<- *prometheus.Desc) {
+	s.c.Describe(descs)
+}
+
+func (s *scopedCollector) Collect(metrics chan<- prometheus.Metric) {
+	s.c.collect(s.scope, metrics)
+}
+
+// RuleMetric exports the value of a server rule as the server_rule metric.
+type RuleMetric struct {
+	// Rule is the name of the rule, for example mp_timelimit.
+	Rule string
+	// Name is the value of the rule label. The default is the name of the rule.
+	Name string
+}
+
+func (c *Collector) collectRules(rules *a2s.RulesInfo, add adder) {
+	if rules == nil {
+		return
+	}
+
+	add("server_rules", float64(len(rules.Rules)))
+
+	for _, ruleMetric := range c.ruleMetrics {
+		value, ok := parseRuleValue(rules.Rules[ruleMetric.Rule])
+		if !ok {
+			continue
+		}
+
+		name := ruleMetric.Name
+		if name == "" {
+			name = ruleMetric.Rule
+		}
+		add("server_rule", value, name)
+	}
+}
+
+// parseRuleValue parses a numeric or boolean rule value.
+func parseRuleValue(s string) (float64, bool) {
+	if value, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+		return value, true
+	}
+	if value, err := strconv.ParseBool(strings.TrimSpace(s)); err == nil {
+		if value {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
@@ -0,0 +1,189 @@
+package collector
+
+import (
+	"sort"
+	"time"
+
+	"github.com/rumblefrog/go-a2s"
+)
+
+// sessionTolerance is how far apart the connect times derived from two player lists may be for a player to be
+// considered the same session. It absorbs clock and rounding differences between queries.
+const sessionTolerance = 15 * time.Second
+
+// connectedAt is when a player in a player list queried at now connected, derived from the reported duration.
+func connectedAt(now time.Time, player *a2s.Player) time.Time {
+	return now.Add(-time.Duration(float64(player.Duration) * float64(time.Second)))
+}
+
+// sessionDurationBuckets are the histogram buckets, in seconds, of completed session durations.
+var sessionDurationBuckets = []float64{60, 300, 600, 1800, 3600, 2 * 3600, 4 * 3600, 8 * 3600, 24 * 3600}
+
+// playerSession is a player who is currently connected to the server.
+type playerSession struct {
+	// id identifies the session across queries, since the name does not when players share a name or names are
+	// omitted.
+	id   uint64
+	name string
+	// suffix numbers the sessions of players with the same name, starting at 1. It is kept for the whole session, so
+	// that a player exported with a suffix keeps it while connected.
+	suffix int
+	// connected is when the player connected, derived from the reported duration.
+	connected time.Time
+	// lastSeen is when the player was last in the player list.
+	lastSeen time.Time
+}
+
+// sessionChanges are the joins and leaves detected by an update.
+type sessionChanges struct {
+	joined []playerSession
+	left   []playerSession
+}
+
+// sessionTracker follows players between queries to detect joins and leaves, without exporting per-player series.
+type sessionTracker struct {
+	initialized bool
+	// active are the sessions of the players in the latest player list, in the same order.
+	active []playerSession
+	nextID uint64
+
+	joins  float64
+	leaves float64
+
+	// Histogram of completed session durations.
+	durationCount   uint64
+	durationSum     float64
+	durationBuckets map[float64]uint64
+}
+
+// update matches the player list against the active sessions. A player matches a session if the name is the same and
+// the derived connect time is close, so a player who reconnects between queries starts a new session.
+func (t *sessionTracker) update(now time.Time, players []*a2s.Player) sessionChanges {
+	var changes sessionChanges
+
+	current := make([]playerSession, 0, len(players))
+	matched := make([]bool, len(t.active))
+
+	for _, player := range players {
+		session := playerSession{
+			name:      player.Name,
+			connected: connectedAt(now, player),
+			lastSeen:  now,
+		}
+
+		// Find the closest unmatched session with the same name.
+		best := -1
+		var bestDiff time.Duration
+		for i, prev := range t.active {
+			if matched[i] || prev.name != session.name {
+				continue
+			}
+			diff := prev.connected.Sub(session.connected)
+			if diff < 0 {
+				diff = -diff
+			}
+			if diff <= sessionTolerance && (best < 0 || diff < bestDiff) {
+				best, bestDiff = i, diff
+			}
+		}
+
+		if best >= 0 {
+			matched[best] = true
+			// Keep the original connect time so that it does not drift.
+			session.id = t.active[best].id
+			session.suffix = t.active[best].suffix
+			session.connected = t.active[best].connected
+		} else {
+			t.nextID++
+			session.id = t.nextID
+			if t.initialized {
+				changes.joined = append(changes.joined, session)
+			}
+		}
+
+		current = append(current, session)
+	}
+
+	assignSuffixes(current)
+
+	for i, prev := range t.active {
+		if !matched[i] {
+			changes.left = append(changes.left, prev)
+			t.observeSession(prev.lastSeen.Sub(prev.connected))
+		}
+	}
+
+	// The first player list is a baseline, since the players may have joined long before the exporter started.
+	t.initialized = true
+	t.active = current
+	t.joins += float64(len(changes.joined))
+	t.leaves += float64(len(changes.left))
+
+	return changes
+}
+
+// assignSuffixes numbers the new sessions, which have no suffix yet, with the lowest suffix which no other session with
+// the same name has. The players who connected first are numbered first.
+func assignSuffixes(sessions []playerSession) {
+	var unnumbered []int
+	taken := make(map[string]map[int]bool)
+	for i, session := range sessions {
+		if session.suffix == 0 {
+			unnumbered = append(unnumbered, i)
+			continue
+		}
+		if taken[session.name] == nil {
+			taken[session.name] = make(map[int]bool)
+		}
+		taken[session.name][session.suffix] = true
+	}
+
+	sort.SliceStable(unnumbered, func(i, j int) bool {
+		return sessions[unnumbered[i]].connected.Before(sessions[unnumbered[j]].connected)
+	})
+
+	for _, i := range unnumbered {
+		name := sessions[i].name
+		if taken[name] == nil {
+			taken[name] = make(map[int]bool)
+		}
+		suffix := 1
+		for taken[name][suffix] {
+			suffix++
+		}
+		taken[name][suffix] = true
+		sessions[i].suffix = suffix
+	}
+}
+
+func newSessionTracker() sessionTracker {
+	buckets := make(map[float64]uint64, len(sessionDurationBuckets))
+	for _, bucket := range sessionDurationBuckets {
+		buckets[bucket] = 0
+	}
+	return sessionTracker{durationBuckets: buckets}
+}
+
+func (t *sessionTracker) observeSession(d time.Duration) {
+	seconds := d.Seconds()
+	if seconds < 0 {
+		seconds = 0
+	}
+
+	t.durationCount++
+	t.durationSum += seconds
+	for _, bucket := range sessionDurationBuckets {
+		if seconds <= bucket {
+			t.durationBuckets[bucket]++
+		}
+	}
+}
+
+func (c *Collector) collectSessions(add adder, addHistogram histogramAdder) {
+	t := &c.sessions
+
+	add("player_joins_total", t.joins)
+	add("player_leaves_total", t.leaves)
+	add("player_sessions_active", float64(len(t.active)))
+	addHistogram("player_session_duration_seconds", t.durationCount, t.durationSum, t.durationBuckets)
+}
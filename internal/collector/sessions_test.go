@@ -0,0 +1,125 @@
+package collector_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	io_prometheus_client "github.com/prometheus/client_model/go"
+	"github.com/rumblefrog/go-a2s"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+	"github.com/armsnyder/a2s-exporter/internal/testserver"
+)
+
+func TestCollector_Sessions(t *testing.T) {
+	srv := &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo"},
+		PlayerInfo: &a2s.PlayerInfo{Count: 2, Players: []*a2s.Player{
+			{Name: "jon", Duration: 32},
+			{Name: "alice", Duration: 64},
+		}},
+	}
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector.New("", testServe(t, srv), collector.Options{}))
+
+	// The first player list is a baseline, so there are no joins yet.
+	metrics := testGather(t, registry)
+	testAssertValue(t, metrics, "player_joins_total", 0)
+	testAssertValue(t, metrics, "player_leaves_total", 0)
+	testAssertValue(t, metrics, "player_sessions_active", 2)
+	testAssertValue(t, metrics, "player_session_duration_seconds", 0)
+
+	// jon stays, alice leaves, bob joins, and a second alice joins under the same name.
+	srv.Update(func() {
+		srv.PlayerInfo = &a2s.PlayerInfo{Count: 3, Players: []*a2s.Player{
+			{Name: "jon", Duration: 33},
+			{Name: "bob", Duration: 1},
+			{Name: "alice", Duration: 2},
+		}}
+	})
+
+	metrics = testGather(t, registry)
+	testAssertValue(t, metrics, "player_joins_total", 2)
+	testAssertValue(t, metrics, "player_leaves_total", 1)
+	testAssertValue(t, metrics, "player_sessions_active", 3)
+	testAssertValue(t, metrics, "player_session_duration_seconds", 1)
+
+	// Everybody leaves.
+	srv.Update(func() {
+		srv.PlayerInfo = &a2s.PlayerInfo{}
+	})
+
+	metrics = testGather(t, registry)
+	testAssertValue(t, metrics, "player_joins_total", 2)
+	testAssertValue(t, metrics, "player_leaves_total", 4)
+	testAssertValue(t, metrics, "player_sessions_active", 0)
+	testAssertValue(t, metrics, "player_session_duration_seconds", 4)
+}
+
+func TestCollector_Stateless(t *testing.T) {
+	srv := &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo", Map: "de_dust2", Players: 1},
+		PlayerInfo: &a2s.PlayerInfo{Count: 1, Players: []*a2s.Player{{Name: "jon", Score: 3, Duration: 32}}},
+	}
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector.New("", testServe(t, srv), collector.Options{Stateless: true}))
+
+	metrics := testGather(t, registry)
+	testAssertValue(t, metrics, "server_players", 1)
+
+	for _, family := range metrics {
+		switch family.GetName() {
+		case "player_joins_total", "player_score_total", "round_changes_total", "map_changes_total",
+			"server_restarts_total", "server_start_timestamp_seconds", "current_map_start_timestamp_seconds":
+			t.Errorf("expected %s not to be exported by a stateless collector", family.GetName())
+		}
+	}
+}
+
+// testGather gathers metrics from the registry.
+func testGather(t *testing.T, registry prometheus.Gatherer) []*io_prometheus_client.MetricFamily {
+	t.Helper()
+	metrics, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return metrics
+}
+
+// testAssertValue checks the value of a metric which has a single series. Counters and gauges are compared by value,
+// and histograms and summaries by sample count.
+func testAssertValue(t *testing.T, metricFamilies []*io_prometheus_client.MetricFamily, name string, want float64) {
+	t.Helper()
+
+	for _, family := range metricFamilies {
+		if family.GetName() != name {
+			continue
+		}
+
+		if len(family.GetMetric()) != 1 {
+			t.Errorf("metric %s count mismatch: wanted 1, got %d", name, len(family.GetMetric()))
+			return
+		}
+
+		metric := family.GetMetric()[0]
+		var got float64
+		switch {
+		case metric.GetCounter() != nil:
+			got = metric.GetCounter().GetValue()
+		case metric.GetGauge() != nil:
+			got = metric.GetGauge().GetValue()
+		case metric.GetHistogram() != nil:
+			got = float64(metric.GetHistogram().GetSampleCount())
+		case metric.GetSummary() != nil:
+			got = float64(metric.GetSummary().GetSampleCount())
+		}
+
+		if math.Abs(got-want) > 1e-9 {
+			t.Errorf("metric %s: wanted %v, got %v", name, want, got)
+		}
+		return
+	}
+
+	t.Errorf("exected metric %s not found", name)
+}
@@ -0,0 +1,275 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rumblefrog/go-a2s"
+)
+
+// stateVersion is the version of the saved state format. It must be incremented whenever the format changes in a way
+// which older or newer versions would misread, so that incompatible state is discarded rather than restored.
+const stateVersion = 2
+
+// savedState is the derived state of a Collector which outlives a single query.
+type savedState struct {
+	Version int       `json:"version"`
+	SavedAt time.Time `json:"saved_at"`
+
+	// LastQueryTime and ServerUp are from the last query, so that transitions can be detected after a restart.
+	LastQueryTime  time.Time       `json:"last_query_time"`
+	ServerUp       bool            `json:"server_up"`
+	LastServerInfo *a2s.ServerInfo `json:"last_server_info,omitempty"`
+	// PlayerNameMode and PlayerNameFingerprint are how the player names in the state were anonymized.
+	PlayerNameMode        PlayerNameMode `json:"player_name_mode"`
+	PlayerNameFingerprint string         `json:"player_name_fingerprint,omitempty"`
+	Sessions              savedSessions  `json:"sessions"`
+	Stats                 savedStats     `json:"stats"`
+	Rounds                savedRounds    `json:"rounds"`
+	Maps                  savedMaps      `json:"maps"`
+	Restarts              savedRestarts  `json:"restarts"`
+}
+
+type savedSessions struct {
+	Initialized bool                 `json:"initialized"`
+	Active      []savedPlayerSession `json:"active"`
+	NextID      uint64               `json:"next_id"`
+	Joins       float64              `json:"joins"`
+	Leaves      float64              `json:"leaves"`
+
+	DurationCount uint64  `json:"duration_count"`
+	DurationSum   float64 `json:"duration_sum"`
+	// DurationBuckets are the cumulative counts in the order of sessionDurationBuckets.
+	DurationBuckets []uint64 `json:"duration_buckets"`
+}
+
+type savedPlayerSession struct {
+	ID        uint64    `json:"id"`
+	Suffix    int       `json:"suffix"`
+	Name      string    `json:"name"`
+	Connected time.Time `json:"connected"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+type savedStats struct {
+	Peaks         []savedPeakSample `json:"peaks"`
+	UniqueDay     time.Time         `json:"unique_day"`
+	UniqueNames   []string          `json:"unique_names"`
+	PlayerSeconds float64           `json:"player_seconds"`
+	LastTime      time.Time         `json:"last_time"`
+	LastPlayers   int               `json:"last_players"`
+}
+
+type savedRounds struct {
+	Initialized bool                        `json:"initialized"`
+	Pending     bool                        `json:"pending"`
+	Players     map[uint64]savedRoundPlayer `json:"players"`
+	Changes     float64                     `json:"changes"`
+}
+
+type savedRoundPlayer struct {
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+	Total float64 `json:"total"`
+}
+
+type savedMaps struct {
+	Current  string             `json:"current"`
+	Start    time.Time          `json:"start"`
+	LastTime time.Time          `json:"last_time"`
+	Changes  float64            `json:"changes"`
+	Seconds  map[string]float64 `json:"seconds"`
+}
+
+type savedRestarts struct {
+	Start          time.Time `json:"start"`
+	Restarts       float64   `json:"restarts"`
+	VersionChanges float64   `json:"version_changes"`
+	LastUp         time.Time `json:"last_up"`
+	DownSince      time.Time `json:"down_since"`
+	Failures       int       `json:"failures"`
+}
+
+type savedPeakSample struct {
+	Minute  time.Time `json:"minute"`
+	Players int       `json:"players"`
+}
+
+// SaveState returns the derived state of the collector, such as player sessions and rolling statistics, encoded so
+// that it can be restored with RestoreState after the exporter restarts.
+func (c *Collector) SaveState() ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state := savedState{
+		Version:               stateVersion,
+		SavedAt:               time.Now(),
+		LastQueryTime:         c.status.Time,
+		ServerUp:              c.status.ServerUp,
+		LastServerInfo:        c.lastServerInfo,
+		PlayerNameMode:        c.playerNameMode,
+		PlayerNameFingerprint: c.playerNameFingerprint(),
+		Sessions: savedSessions{
+			Initialized:   c.sessions.initialized,
+			NextID:        c.sessions.nextID,
+			Joins:         c.sessions.joins,
+			Leaves:        c.sessions.leaves,
+			DurationCount: c.sessions.durationCount,
+			DurationSum:   c.sessions.durationSum,
+		},
+		Stats: savedStats{
+			UniqueDay:     c.stats.uniqueDay,
+			PlayerSeconds: c.stats.playerSeconds,
+			LastTime:      c.stats.lastTime,
+			LastPlayers:   c.stats.lastPlayers,
+		},
+	}
+
+	for _, session := range c.sessions.active {
+		state.Sessions.Active = append(state.Sessions.Active, savedPlayerSession{
+			ID:        session.id,
+			Suffix:    session.suffix,
+			Name:      session.name,
+			Connected: session.connected,
+			LastSeen:  session.lastSeen,
+		})
+	}
+	for _, bucket := range sessionDurationBuckets {
+		state.Sessions.DurationBuckets = append(state.Sessions.DurationBuckets, c.sessions.durationBuckets[bucket])
+	}
+
+	for _, sample := range c.stats.peaks {
+		state.Stats.Peaks = append(state.Stats.Peaks, savedPeakSample{Minute: sample.minute, Players: sample.players})
+	}
+	for name := range c.stats.uniqueNames {
+		state.Stats.UniqueNames = append(state.Stats.UniqueNames, name)
+	}
+
+	state.Rounds = savedRounds{
+		Initialized: c.rounds.initialized,
+		Pending:     c.rounds.pending,
+		Players:     make(map[uint64]savedRoundPlayer, len(c.rounds.players)),
+		Changes:     c.rounds.changes,
+	}
+	for id, player := range c.rounds.players {
+		state.Rounds.Players[id] = savedRoundPlayer{Name: player.name, Score: player.score, Total: player.total}
+	}
+
+	state.Maps = savedMaps{
+		Current:  c.maps.current,
+		Start:    c.maps.start,
+		LastTime: c.maps.lastTime,
+		Changes:  c.maps.changes,
+		Seconds:  c.maps.seconds,
+	}
+
+	state.Restarts = savedRestarts{
+		Start:          c.restarts.start,
+		Restarts:       c.restarts.restarts,
+		VersionChanges: c.restarts.versionChanges,
+		LastUp:         c.restarts.lastUp,
+		DownSince:      c.restarts.downSince,
+		Failures:       c.restarts.failures,
+	}
+
+	return json.Marshal(state)
+}
+
+// RestoreState restores state returned by SaveState. It must be called before the collector is first queried. State
+// saved by an incompatible version of the exporter is rejected, and the collector is left unchanged.
+func (c *Collector) RestoreState(data []byte) error {
+	var state savedState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	if state.Version != stateVersion {
+		return fmt.Errorf("unsupported state version %d", state.Version)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.restoredStatus = Status{Time: state.LastQueryTime, ServerUp: state.ServerUp}
+	c.lastServerInfo = state.LastServerInfo
+
+	// The state which is keyed by player name is discarded if the names were anonymized differently, since they would
+	// not match. The first query is then a new baseline for it.
+	namesMatch := state.PlayerNameMode == c.playerNameMode && state.PlayerNameFingerprint == c.playerNameFingerprint()
+	if !namesMatch {
+		state.Sessions.Initialized = false
+		state.Sessions.Active = nil
+		state.Stats.UniqueNames = nil
+		state.Rounds.Initialized = false
+		state.Rounds.Players = nil
+	}
+
+	c.sessions.initialized = state.Sessions.Initialized
+	c.sessions.active = nil
+	for _, session := range state.Sessions.Active {
+		c.sessions.active = append(c.sessions.active, playerSession{
+			id:        session.ID,
+			suffix:    session.Suffix,
+			name:      session.Name,
+			connected: session.Connected,
+			lastSeen:  session.LastSeen,
+		})
+	}
+	c.sessions.nextID = state.Sessions.NextID
+	c.sessions.joins = state.Sessions.Joins
+	c.sessions.leaves = state.Sessions.Leaves
+
+	// The histogram is only restored if the buckets are unchanged.
+	if len(state.Sessions.DurationBuckets) == len(sessionDurationBuckets) {
+		c.sessions.durationCount = state.Sessions.DurationCount
+		c.sessions.durationSum = state.Sessions.DurationSum
+		for i, bucket := range sessionDurationBuckets {
+			c.sessions.durationBuckets[bucket] = state.Sessions.DurationBuckets[i]
+		}
+	}
+
+	c.stats.peaks = nil
+	for _, sample := range state.Stats.Peaks {
+		c.stats.peaks = append(c.stats.peaks, peakSample{minute: sample.Minute, players: sample.Players})
+	}
+	c.stats.uniqueDay = state.Stats.UniqueDay
+	c.stats.uniqueNames = nil
+	if state.Stats.UniqueNames != nil {
+		c.stats.uniqueNames = make(map[string]struct{}, len(state.Stats.UniqueNames))
+		for _, name := range state.Stats.UniqueNames {
+			c.stats.uniqueNames[name] = struct{}{}
+		}
+	}
+	c.stats.playerSeconds = state.Stats.PlayerSeconds
+	c.stats.lastTime = state.Stats.LastTime
+	c.stats.lastPlayers = state.Stats.LastPlayers
+
+	c.rounds = roundTracker{
+		initialized: state.Rounds.Initialized,
+		pending:     state.Rounds.Pending,
+		players:     make(map[uint64]roundPlayer, len(state.Rounds.Players)),
+		changes:     state.Rounds.Changes,
+	}
+	for id, player := range state.Rounds.Players {
+		c.rounds.players[id] = roundPlayer{name: player.Name, score: player.Score, total: player.Total}
+	}
+
+	c.maps = mapTracker{
+		current:  state.Maps.Current,
+		start:    state.Maps.Start,
+		lastTime: state.Maps.LastTime,
+		changes:  state.Maps.Changes,
+		seconds:  state.Maps.Seconds,
+	}
+
+	c.restarts = restartTracker{
+		start:          state.Restarts.Start,
+		restarts:       state.Restarts.Restarts,
+		versionChanges: state.Restarts.VersionChanges,
+		lastUp:         state.Restarts.LastUp,
+		downSince:      state.Restarts.DownSince,
+		failures:       state.Restarts.Failures,
+	}
+
+	return nil
+}
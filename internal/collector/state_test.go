@@ -0,0 +1,108 @@
+package collector_test
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rumblefrog/go-a2s"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+	"github.com/armsnyder/a2s-exporter/internal/events"
+	"github.com/armsnyder/a2s-exporter/internal/testserver"
+)
+
+func TestCollector_SaveRestoreState(t *testing.T) {
+	srv := &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo", Map: "de_dust2", Players: 2},
+		PlayerInfo: &a2s.PlayerInfo{Count: 2, Players: []*a2s.Player{
+			{Name: "jon", Duration: 32},
+			{Name: "alice", Duration: 64},
+		}},
+	}
+	addr := testServe(t, srv)
+
+	before := collector.New("", addr, collector.Options{})
+	before.Refresh()
+
+	// bob joins.
+	srv.Update(func() {
+		srv.PlayerInfo = &a2s.PlayerInfo{Count: 3, Players: []*a2s.Player{
+			{Name: "jon", Duration: 33},
+			{Name: "alice", Duration: 65},
+			{Name: "bob", Duration: 1},
+		}}
+	})
+	before.Refresh()
+
+	data, err := before.SaveState()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The restored collector continues where the first one left off, so alice leaving is detected and bob is not
+	// counted again.
+	srv.Update(func() {
+		srv.PlayerInfo = &a2s.PlayerInfo{Count: 2, Players: []*a2s.Player{
+			{Name: "jon", Duration: 34},
+			{Name: "bob", Duration: 2},
+		}}
+	})
+
+	sink := &testSink{}
+	after := collector.New("", addr, collector.Options{Events: sink})
+	if err := after.RestoreState(data); err != nil {
+		t.Fatal(err)
+	}
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(after)
+
+	metrics := testGather(t, registry)
+	testAssertValue(t, metrics, "player_joins_total", 1)
+	testAssertValue(t, metrics, "player_leaves_total", 1)
+	testAssertValue(t, metrics, "player_unique_names_today", 3)
+	testAssertEventTypes(t, sink.types(), events.PlayerLeave)
+}
+
+func TestCollector_RestoreState_UnsupportedVersion(t *testing.T) {
+	c := collector.New("", "127.0.0.1:0", collector.Options{})
+	if err := c.RestoreState([]byte(`{"version":999}`)); err == nil {
+		t.Error("wanted an error for an unsupported version")
+	}
+}
+
+func TestCollector_RestoreState_OtherPlayerNameSecret(t *testing.T) {
+	srv := &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo", Map: "de_dust2", Players: 2},
+		PlayerInfo: &a2s.PlayerInfo{Count: 2, Players: []*a2s.Player{
+			{Name: "jon", Duration: 32},
+			{Name: "alice", Duration: 64},
+		}},
+	}
+	addr := testServe(t, srv)
+
+	before := collector.New("", addr, collector.Options{PlayerNameMode: collector.PlayerNameHashed, PlayerNameSecret: []byte("a")})
+	before.Refresh()
+
+	data, err := before.SaveState()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The names hash differently with the new secret, so the saved players are not matched with the same players
+	// under their new names.
+	sink := &testSink{}
+	after := collector.New("", addr, collector.Options{PlayerNameMode: collector.PlayerNameHashed, PlayerNameSecret: []byte("b"), Events: sink})
+	if err := after.RestoreState(data); err != nil {
+		t.Fatal(err)
+	}
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(after)
+
+	metrics := testGather(t, registry)
+	testAssertValue(t, metrics, "player_joins_total", 0)
+	testAssertValue(t, metrics, "player_leaves_total", 0)
+	testAssertValue(t, metrics, "player_unique_names_today", 2)
+	testAssertEventTypes(t, sink.types())
+}
@@ -0,0 +1,115 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/rumblefrog/go-a2s"
+)
+
+// peakWindows are the windows over which the peak number of concurrent players is exported, by label value.
+var peakWindows = []struct {
+	label    string
+	duration time.Duration
+}{
+	{"1h", time.Hour},
+	{"24h", 24 * time.Hour},
+}
+
+// maxQueryGap is the longest time between two queries which counts towards the time based counters, such as
+// player_seconds_total. A longer gap means the exporter was not being scraped, so what happened in between is unknown.
+const maxQueryGap = 10 * time.Minute
+
+// peakSample is the highest player count seen during a minute.
+type peakSample struct {
+	minute  time.Time
+	players int
+}
+
+// statsTracker computes rolling player statistics over time.
+type statsTracker struct {
+	// peaks holds a sample per minute, oldest first, for the longest peak window.
+	peaks []peakSample
+
+	// uniqueDay is the start of the day for which uniqueNames are counted.
+	uniqueDay   time.Time
+	uniqueNames map[string]struct{}
+
+	playerSeconds float64
+	lastTime      time.Time
+	lastPlayers   int
+}
+
+// update records the player count and player list of a query. Either may be nil if its query failed.
+func (t *statsTracker) update(now time.Time, serverInfo *a2s.ServerInfo, playerInfo *a2s.PlayerInfo) {
+	if serverInfo != nil {
+		players := int(serverInfo.Players)
+
+		minute := now.Truncate(time.Minute)
+		if n := len(t.peaks); n > 0 && t.peaks[n-1].minute.Equal(minute) {
+			if players > t.peaks[n-1].players {
+				t.peaks[n-1].players = players
+			}
+		} else {
+			t.peaks = append(t.peaks, peakSample{minute: minute, players: players})
+		}
+
+		// Drop the samples which are older than the longest window.
+		cutoff := now.Add(-peakWindows[len(peakWindows)-1].duration)
+		for len(t.peaks) > 0 && !t.peaks[0].minute.After(cutoff) {
+			t.peaks = t.peaks[1:]
+		}
+
+		// Integrate the player count over time.
+		if !t.lastTime.IsZero() {
+			if gap := now.Sub(t.lastTime); gap > 0 && gap <= maxQueryGap {
+				t.playerSeconds += float64(t.lastPlayers) * gap.Seconds()
+			}
+		}
+		t.lastTime = now
+		t.lastPlayers = players
+	} else {
+		// The server is down, so the time until it is back does not count.
+		t.lastTime = time.Time{}
+	}
+
+	if playerInfo != nil {
+		year, month, day := now.Date()
+		today := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+		if !t.uniqueDay.Equal(today) || t.uniqueNames == nil {
+			t.uniqueDay = today
+			t.uniqueNames = make(map[string]struct{})
+		}
+
+		for _, player := range playerInfo.Players {
+			if player.Name != "" {
+				t.uniqueNames[player.Name] = struct{}{}
+			}
+		}
+	}
+}
+
+// peak returns the highest player count seen within the window.
+func (t *statsTracker) peak(now time.Time, window time.Duration) int {
+	var peak int
+	cutoff := now.Add(-window)
+	for _, sample := range t.peaks {
+		if sample.minute.After(cutoff) && sample.players > peak {
+			peak = sample.players
+		}
+	}
+	return peak
+}
+
+func (c *Collector) collectStats(add adder) {
+	now := c.status.Time
+
+	for _, window := range peakWindows {
+		add("player_peak", float64(c.stats.peak(now, window.duration)), window.label)
+	}
+
+	if c.stats.uniqueNames != nil {
+		add("player_unique_names_today", float64(len(c.stats.uniqueNames)))
+	}
+
+	add("player_seconds_total", c.stats.playerSeconds)
+}
@@ -0,0 +1,65 @@
+package collector_test
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rumblefrog/go-a2s"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+	"github.com/armsnyder/a2s-exporter/internal/testserver"
+)
+
+func TestCollector_Stats(t *testing.T) {
+	srv := &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo", Players: 5},
+		PlayerInfo: &a2s.PlayerInfo{Count: 2, Players: []*a2s.Player{
+			{Name: "jon", Duration: 32},
+			{Name: "alice", Duration: 64},
+		}},
+	}
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector.New("", testServe(t, srv), collector.Options{}))
+
+	metrics := testGather(t, registry)
+	testAssertGauge(t, metrics, "player_peak",
+		expectGauge{value: 5, labels: map[string]string{"window": "1h"}},
+		expectGauge{value: 5, labels: map[string]string{"window": "24h"}},
+	)
+	testAssertValue(t, metrics, "player_unique_names_today", 2)
+	testAssertValue(t, metrics, "player_seconds_total", 0)
+
+	// Fewer players are on now, but the peak is kept, and the new name is counted once.
+	srv.Update(func() {
+		srv.ServerInfo.Players = 3
+		srv.PlayerInfo = &a2s.PlayerInfo{Count: 2, Players: []*a2s.Player{
+			{Name: "jon", Duration: 33},
+			{Name: "bob", Duration: 1},
+		}}
+	})
+
+	metrics = testGather(t, registry)
+	testAssertGauge(t, metrics, "player_peak",
+		expectGauge{value: 5, labels: map[string]string{"window": "1h"}},
+		expectGauge{value: 5, labels: map[string]string{"window": "24h"}},
+	)
+	testAssertValue(t, metrics, "player_unique_names_today", 3)
+}
+
+func TestCollector_Stats_OmittedNames(t *testing.T) {
+	srv := &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo", Players: 1},
+		PlayerInfo: &a2s.PlayerInfo{Count: 1, Players: []*a2s.Player{{Name: "jon", Duration: 32}}},
+	}
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector.New("", testServe(t, srv), collector.Options{PlayerNameMode: collector.PlayerNameOmitted}))
+
+	metrics := testGather(t, registry)
+	testAssertValue(t, metrics, "player_seconds_total", 0)
+
+	for _, family := range metrics {
+		if family.GetName() == "player_unique_names_today" {
+			t.Error("expected unique names not to be exported when names are omitted")
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Package config loads the optional exporter configuration file.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+	"github.com/armsnyder/a2s-exporter/internal/notifier"
+)
+
+// Config is the exporter configuration file. Commandline flags and environment variables cover the basic options;
+// the file holds the options which do not fit in a flag.
+type Config struct {
+	Notifiers []notifier.Config `yaml:"notifiers"`
+	Targets   []Target          `yaml:"targets"`
+	Modules   map[string]Module `yaml:"modules"`
+}
+
+// Target holds the settings of a single A2S server, matched by its query address. They apply to the server given by
+// --address and to probes of the server.
+type Target struct {
+	Address string `yaml:"address"`
+	// Alias is the value of the server_alias identity label.
+	Alias string `yaml:"alias"`
+	// Labels are added to every metric of the server.
+	Labels map[string]string `yaml:"labels"`
+	// Module is the name of the module whose settings apply to the server, unless a probe selects another one.
+	Module string `yaml:"module"`
+}
+
+// Options returns the collector options for the server at addr: the settings of the module, which is the named one or
+// else that of the target, applied to opts. An unknown module is an error.
+func (c *Config) Options(addr, module string, opts collector.Options) (collector.Options, error) {
+	if module == "" {
+		if target := c.Target(addr); target != nil {
+			module = target.Module
+		}
+	}
+	if module == "" {
+		return opts, nil
+	}
+
+	m, ok := c.Modules[module]
+	if !ok {
+		return opts, fmt.Errorf("unknown module %s", module)
+	}
+	return m.Apply(opts)
+}
+
+// Target returns the settings of the server at addr, or nil if there are none.
+func (c *Config) Target(addr string) *Target {
+	for i := range c.Targets {
+		if c.Targets[i].Address == addr {
+			return &c.Targets[i]
+		}
+	}
+	return nil
+}
+
+// Load reads and validates the configuration file at path. Unknown fields are rejected, to catch typos.
+func Load(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(b))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+
+	for i, notifierCfg := range cfg.Notifiers {
+		if notifierCfg.Name == "" {
+			cfg.Notifiers[i].Name = fmt.Sprintf("%d", i)
+		}
+	}
+
+	for name, module := range cfg.Modules {
+		if err := module.validate(); err != nil {
+			return nil, fmt.Errorf("%s: module %s: %w", path, name, err)
+		}
+	}
+
+	seen := make(map[string]bool, len(cfg.Targets))
+	for _, target := range cfg.Targets {
+		if target.Address == "" {
+			return nil, fmt.Errorf("%s: target is missing an address", path)
+		}
+		if seen[target.Address] {
+			return nil, fmt.Errorf("%s: duplicate target %s", path, target.Address)
+		}
+		seen[target.Address] = true
+
+		if err := collector.ValidateLabels(target.Labels); err != nil {
+			return nil, fmt.Errorf("%s: target %s: %w", path, target.Address, err)
+		}
+		if _, ok := cfg.Modules[target.Module]; target.Module != "" && !ok {
+			return nil, fmt.Errorf("%s: target %s: unknown module %s", path, target.Address, target.Module)
+		}
+	}
+
+	return cfg, nil
+}
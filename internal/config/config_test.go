@@ -0,0 +1,113 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/armsnyder/a2s-exporter/internal/config"
+	"github.com/armsnyder/a2s-exporter/internal/events"
+	"github.com/armsnyder/a2s-exporter/internal/notifier"
+)
+
+func TestLoad(t *testing.T) {
+	path := testWriteConfig(t, `
+notifiers:
+  - name: discord
+    type: discord
+    url: https://discord.example.com/webhook
+    debounce: 5m
+    events:
+      - type: server_down
+        debounce: 1m
+      - type: map_change
+        template: "Now playing {{ .Map }}"
+  - type: json
+    url: https://example.com/hook
+`)
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cfg.Notifiers) != 2 {
+		t.Fatalf("expected 2 notifiers but got %d", len(cfg.Notifiers))
+	}
+
+	discord := cfg.Notifiers[0]
+	if discord.Name != "discord" || discord.Kind != notifier.Discord || discord.Debounce != 5*time.Minute {
+		t.Errorf("unexpected notifier %+v", discord)
+	}
+	if len(discord.Events) != 2 || discord.Events[0].Type != events.ServerDown || *discord.Events[0].Debounce != time.Minute {
+		t.Errorf("unexpected events %+v", discord.Events)
+	}
+
+	// Unnamed notifiers are named by index.
+	if cfg.Notifiers[1].Name != "1" {
+		t.Errorf("expected name 1 but got %q", cfg.Notifiers[1].Name)
+	}
+}
+
+func TestLoad_Empty(t *testing.T) {
+	if _, err := config.Load(testWriteConfig(t, "")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLoad_UnknownField(t *testing.T) {
+	if _, err := config.Load(testWriteConfig(t, "notifierz: []\n")); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestLoad_Targets(t *testing.T) {
+	path := testWriteConfig(t, `
+targets:
+  - address: myserver.example.com:27015
+    alias: eu-1
+    labels:
+      env: prod
+      region: eu
+`)
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target := cfg.Target("myserver.example.com:27015")
+	if target == nil {
+		t.Fatal("expected a target")
+	}
+	if target.Alias != "eu-1" || target.Labels["env"] != "prod" || target.Labels["region"] != "eu" {
+		t.Errorf("unexpected target %+v", target)
+	}
+
+	if cfg.Target("other.example.com:27015") != nil {
+		t.Error("expected no target for an unknown address")
+	}
+}
+
+func TestLoad_InvalidTargets(t *testing.T) {
+	for name, content := range map[string]string{
+		"missing address": "targets:\n  - alias: eu-1\n",
+		"duplicate":       "targets:\n  - address: a:1\n  - address: a:1\n",
+		"invalid label":   "targets:\n  - address: a:1\n    labels:\n      server_name: foo\n",
+	} {
+		if _, err := config.Load(testWriteConfig(t, content)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// testWriteConfig writes a config file and returns its path.
+func testWriteConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
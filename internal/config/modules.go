@@ -0,0 +1,175 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rumblefrog/go-a2s"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+)
+
+// Module is a named set of collector settings, in the style of the blackbox exporter modules. It is selected by a
+// target in the configuration file, or with the module parameter of a probe. Unset fields keep the settings of the
+// game profile, or else those of the commandline.
+type Module struct {
+	// Game selects a built-in game profile, see gameProfiles.
+	Game string `yaml:"game"`
+	// Timeout of each A2S query.
+	Timeout time.Duration `yaml:"timeout"`
+	// MaxPacketSize is the max packet size of the A2S query server.
+	MaxPacketSize uint32 `yaml:"max_packet_size"`
+	// PreOrangeBox selects the packet format of servers running an engine older than the Source Orange Box, such as
+	// GoldSrc.
+	PreOrangeBox *bool `yaml:"pre_orange_box"`
+
+	ExcludePlayerMetrics *bool  `yaml:"exclude_player_metrics"`
+	PlayerMode           string `yaml:"player_mode"`
+	DuplicatePlayerMode  string `yaml:"duplicate_player_mode"`
+
+	// QueryRules queries the rules by default. It defaults to true if any rules are mapped.
+	QueryRules *bool `yaml:"query_rules"`
+	// Rules are exported as the server_rule metric.
+	Rules []RuleMapping `yaml:"rules"`
+}
+
+// RuleMapping exports the value of a server rule as the server_rule metric.
+type RuleMapping struct {
+	Rule string `yaml:"rule"`
+	// Name is the value of the rule label. The default is the name of the rule.
+	Name string `yaml:"name"`
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// gameProfiles are the defaults for games with known quirks.
+var gameProfiles = map[string]Module{
+	// Source engine games, such as Counter-Strike and Team Fortress 2, expose their match settings as rules.
+	"source": {
+		Rules: []RuleMapping{
+			{Rule: "mp_timelimit"},
+			{Rule: "mp_fraglimit"},
+			{Rule: "mp_maxrounds"},
+			{Rule: "mp_winlimit"},
+		},
+	},
+	// GoldSrc games, such as Counter-Strike 1.6, split large packets in the pre-Orange Box format.
+	"goldsrc": {
+		PreOrangeBox: boolPtr(true),
+		Rules: []RuleMapping{
+			{Rule: "mp_timelimit"},
+			{Rule: "mp_fraglimit"},
+		},
+	},
+	// Rust assigns player names from a pool of random names, which often contains duplicates.
+	"rust": {
+		DuplicatePlayerMode: string(collector.DuplicateAggregate),
+	},
+}
+
+// withProfile returns the module with the unset fields taken from its game profile.
+func (m Module) withProfile() Module {
+	profile, ok := gameProfiles[m.Game]
+	if !ok {
+		return m
+	}
+
+	if m.Timeout == 0 {
+		m.Timeout = profile.Timeout
+	}
+	if m.MaxPacketSize == 0 {
+		m.MaxPacketSize = profile.MaxPacketSize
+	}
+	if m.PreOrangeBox == nil {
+		m.PreOrangeBox = profile.PreOrangeBox
+	}
+	if m.ExcludePlayerMetrics == nil {
+		m.ExcludePlayerMetrics = profile.ExcludePlayerMetrics
+	}
+	if m.PlayerMode == "" {
+		m.PlayerMode = profile.PlayerMode
+	}
+	if m.DuplicatePlayerMode == "" {
+		m.DuplicatePlayerMode = profile.DuplicatePlayerMode
+	}
+	if m.QueryRules == nil {
+		m.QueryRules = profile.QueryRules
+	}
+	if m.Rules == nil {
+		m.Rules = profile.Rules
+	}
+
+	return m
+}
+
+// Apply returns the collector options with the settings of the module applied.
+func (m Module) Apply(opts collector.Options) (collector.Options, error) {
+	m = m.withProfile()
+
+	// Later client options override earlier ones, so the module's are appended to a copy of the commandline's.
+	opts.ClientOptions = append([]func(*a2s.Client) error{}, opts.ClientOptions...)
+	if m.Timeout > 0 {
+		opts.ClientOptions = append(opts.ClientOptions, a2s.TimeoutOption(m.Timeout))
+	}
+	if m.MaxPacketSize > 0 {
+		opts.ClientOptions = append(opts.ClientOptions, a2s.SetMaxPacketSize(m.MaxPacketSize))
+	}
+	if m.PreOrangeBox != nil {
+		opts.ClientOptions = append(opts.ClientOptions, a2s.PreOrangeBox(*m.PreOrangeBox))
+	}
+
+	if m.ExcludePlayerMetrics != nil {
+		opts.ExcludePlayerMetrics = *m.ExcludePlayerMetrics
+	}
+
+	if m.PlayerMode != "" {
+		playerMode, err := collector.ParsePlayerMode(m.PlayerMode)
+		if err != nil {
+			return opts, err
+		}
+		opts.PlayerMode = playerMode
+	}
+
+	if m.DuplicatePlayerMode != "" {
+		duplicateMode, err := collector.ParseDuplicateMode(m.DuplicatePlayerMode)
+		if err != nil {
+			return opts, err
+		}
+		opts.DuplicateMode = duplicateMode
+	}
+
+	if m.Rules != nil {
+		opts.RuleMetrics = nil
+		for _, mapping := range m.Rules {
+			if mapping.Rule == "" {
+				return opts, fmt.Errorf("rule mapping is missing a rule")
+			}
+			opts.RuleMetrics = append(opts.RuleMetrics, collector.RuleMetric{Rule: mapping.Rule, Name: mapping.Name})
+		}
+		opts.QueryRules = len(m.Rules) > 0
+	}
+	if m.QueryRules != nil {
+		opts.QueryRules = *m.QueryRules
+	}
+
+	return opts, nil
+}
+
+// validate checks the module by applying it to empty options.
+func (m Module) validate() error {
+	if _, ok := gameProfiles[m.Game]; m.Game != "" && !ok {
+		names := make([]string, 0, len(gameProfiles))
+		for name := range gameProfiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown game profile %q (known profiles: %s)", m.Game, strings.Join(names, ", "))
+	}
+
+	_, err := m.Apply(collector.Options{})
+	return err
+}
@@ -0,0 +1,86 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+	"github.com/armsnyder/a2s-exporter/internal/config"
+)
+
+func TestLoad_Modules(t *testing.T) {
+	path := testWriteConfig(t, `
+modules:
+  cs_light:
+    game: source
+    timeout: 2s
+    exclude_player_metrics: true
+  rust_full:
+    game: rust
+    player_mode: both
+    max_packet_size: 1200
+    rules:
+      - rule: fps
+        name: server_fps
+targets:
+  - address: rust.example.com:28016
+    module: rust_full
+`)
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := collector.Options{PlayerMode: collector.PlayerModeLabeled}
+
+	// The game profile fills in the rules, which turns on the rules query.
+	opts, err := cfg.Options("cs.example.com:27015", "cs_light", base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opts.ExcludePlayerMetrics || !opts.QueryRules || len(opts.RuleMetrics) != 4 || opts.RuleMetrics[0].Rule != "mp_timelimit" {
+		t.Errorf("unexpected options %+v", opts)
+	}
+	if len(opts.ClientOptions) != 1 {
+		t.Errorf("expected 1 client option but got %d", len(opts.ClientOptions))
+	}
+
+	// The target selects its module, and the module overrides its game profile.
+	opts, err = cfg.Options("rust.example.com:28016", "", base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.PlayerMode != collector.PlayerModeBoth || opts.DuplicateMode != collector.DuplicateAggregate || !opts.QueryRules {
+		t.Errorf("unexpected options %+v", opts)
+	}
+	if len(opts.RuleMetrics) != 1 || opts.RuleMetrics[0].Name != "server_fps" {
+		t.Errorf("unexpected rule metrics %+v", opts.RuleMetrics)
+	}
+
+	// Without a module, the options are unchanged.
+	opts, err = cfg.Options("other.example.com:27015", "", base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.PlayerMode != collector.PlayerModeLabeled || opts.QueryRules || opts.RuleMetrics != nil {
+		t.Errorf("unexpected options %+v", opts)
+	}
+
+	if _, err := cfg.Options("other.example.com:27015", "nope", base); err == nil {
+		t.Error("expected an error for an unknown module")
+	}
+}
+
+func TestLoad_InvalidModules(t *testing.T) {
+	for name, content := range map[string]string{
+		"unknown game":        "modules:\n  m:\n    game: nope\n",
+		"unknown player mode": "modules:\n  m:\n    player_mode: nope\n",
+		"missing rule":        "modules:\n  m:\n    rules:\n      - name: foo\n",
+		"unknown field":       "modules:\n  m:\n    timeoutz: 1s\n",
+		"unknown module":      "targets:\n  - address: a:1\n    module: nope\n",
+	} {
+		if _, err := config.Load(testWriteConfig(t, content)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
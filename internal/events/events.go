@@ -0,0 +1,128 @@
+// Package events describes notable changes observed on game servers, such as players joining and leaving.
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Type is the kind of an Event.
+type Type string
+
+const (
+	PlayerJoin    Type = "player_join"
+	PlayerLeave   Type = "player_leave"
+	MapChange     Type = "map_change"
+	ServerRestart Type = "server_restart"
+	ServerDown    Type = "server_down"
+	ServerUp      Type = "server_up"
+	ServerFull    Type = "server_full"
+)
+
+// Event is a notable change observed on a game server.
+type Event struct {
+	Time       time.Time `json:"time"`
+	Type       Type      `json:"type"`
+	Target     string    `json:"target"`
+	ServerName string    `json:"server_name,omitempty"`
+
+	// Player events.
+	Player         string     `json:"player,omitempty"`
+	ConnectedAt    *time.Time `json:"connected_at,omitempty"`
+	SessionSeconds float64    `json:"session_seconds,omitempty"`
+
+	// Map change events.
+	Map         string `json:"map,omitempty"`
+	PreviousMap string `json:"previous_map,omitempty"`
+
+	// Server full events.
+	Players    int `json:"players,omitempty"`
+	MaxPlayers int `json:"max_players,omitempty"`
+}
+
+// Sink receives events. Emit is called while the server is being queried, so it should return quickly.
+type Sink interface {
+	Emit(event Event)
+}
+
+// Multi is a Sink which emits events to each of its Sinks.
+type Multi []Sink
+
+func (m Multi) Emit(event Event) {
+	for _, sink := range m {
+		sink.Emit(event)
+	}
+}
+
+// errorLogInterval is the minimum time between two logs of the same write error, so that a full disk does not flood
+// the logs.
+const errorLogInterval = time.Minute
+
+// JSONWriter is a Sink which writes each event as a line of JSON. Write failures are logged, since a Sink cannot
+// return them.
+type JSONWriter struct {
+	// ErrorLog receives the write failures. It defaults to os.Stderr.
+	ErrorLog io.Writer
+
+	mu         sync.Mutex
+	w          io.Writer
+	lastErr    string
+	lastLog    time.Time
+	suppressed int
+}
+
+// NewJSONWriter returns a JSONWriter which writes to w.
+func NewJSONWriter(w io.Writer) *JSONWriter {
+	return &JSONWriter{w: w}
+}
+
+func (j *JSONWriter) Emit(event Event) {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	// A single write per event, so that a rotating file never splits a line.
+	_, err = j.w.Write(line)
+	j.logError(err)
+}
+
+// logError logs a write failure, unless the same failure was logged recently, and logs when writes succeed again.
+func (j *JSONWriter) logError(err error) {
+	out := j.ErrorLog
+	if out == nil {
+		out = os.Stderr
+	}
+
+	if err == nil {
+		if j.lastErr != "" {
+			fmt.Fprintln(out, "Events are written again")
+			j.lastErr = ""
+			j.suppressed = 0
+		}
+		return
+	}
+
+	now := time.Now()
+	if err.Error() == j.lastErr && now.Sub(j.lastLog) < errorLogInterval {
+		j.suppressed++
+		return
+	}
+
+	if j.suppressed > 0 {
+		fmt.Fprintf(out, "Could not write event: %v (%d more failures since the last log)\n", err, j.suppressed)
+	} else {
+		fmt.Fprintln(out, "Could not write event:", err)
+	}
+	j.lastErr = err.Error()
+	j.lastLog = now
+	j.suppressed = 0
+}
@@ -0,0 +1,92 @@
+package events_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/armsnyder/a2s-exporter/internal/events"
+)
+
+func TestJSONWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	sink := events.Multi{events.NewJSONWriter(buf)}
+
+	connected := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	sink.Emit(events.Event{
+		Time:           connected.Add(time.Hour),
+		Type:           events.PlayerLeave,
+		Target:         "127.0.0.1:27015",
+		Player:         "jon",
+		ConnectedAt:    &connected,
+		SessionSeconds: 3600,
+	})
+	sink.Emit(events.Event{
+		Time:        connected.Add(2 * time.Hour),
+		Type:        events.MapChange,
+		Target:      "127.0.0.1:27015",
+		Map:         "de_dust2",
+		PreviousMap: "de_inferno",
+	})
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines but got %d:\n%s", len(lines), buf)
+	}
+
+	want := `{"time":"2024-01-02T04:04:05Z","type":"player_leave","target":"127.0.0.1:27015","player":"jon","connected_at":"2024-01-02T03:04:05Z","session_seconds":3600}`
+	if got := string(lines[0]); got != want {
+		t.Errorf("wanted:\n%s\ngot:\n%s", want, got)
+	}
+
+	var event events.Event
+	if err := json.Unmarshal(lines[1], &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.Type != events.MapChange || event.Map != "de_dust2" || event.PreviousMap != "de_inferno" {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
+
+func TestJSONWriter_Errors(t *testing.T) {
+	out := &failingWriter{err: errors.New("disk full")}
+	errLog := &bytes.Buffer{}
+	sink := events.NewJSONWriter(out)
+	sink.ErrorLog = errLog
+
+	// A repeated failure is logged once.
+	for i := 0; i < 3; i++ {
+		sink.Emit(events.Event{Type: events.ServerUp})
+	}
+	if got := strings.Count(errLog.String(), "disk full"); got != 1 {
+		t.Errorf("expected the failure to be logged once, got:\n%s", errLog)
+	}
+
+	// A different failure is logged right away.
+	out.err = errors.New("permission denied")
+	sink.Emit(events.Event{Type: events.ServerUp})
+	if !strings.Contains(errLog.String(), "permission denied") {
+		t.Errorf("expected the new failure to be logged, got:\n%s", errLog)
+	}
+
+	// Recovery is logged.
+	out.err = nil
+	sink.Emit(events.Event{Type: events.ServerUp})
+	if !strings.Contains(errLog.String(), "written again") {
+		t.Errorf("expected the recovery to be logged, got:\n%s", errLog)
+	}
+}
+
+type failingWriter struct {
+	err error
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,114 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// RotatingFile is an append-only file which is rotated once it would grow beyond a maximum size. Rotated files are
+// renamed with a numeric suffix, for example events.jsonl.1, with the highest number being the oldest.
+type RotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// OpenRotatingFile opens or creates the file at path for appending. A maxSize of zero disables rotation.
+func OpenRotatingFile(path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	r := &RotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// If rotation fails, the file keeps growing rather than losing the event, and rotation is retried on the next write.
+	var rotateErr error
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		rotateErr = r.rotate()
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	if err != nil {
+		return n, err
+	}
+	return n, rotateErr
+}
+
+// Close closes the file.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+func (r *RotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+// rotate renames the file to the first backup and opens a new file. The file at path is reopened even if the
+// renames fail, so that later writes still succeed.
+func (r *RotatingFile) rotate() error {
+	closeErr := r.file.Close()
+	err := r.shiftBackups()
+	if openErr := r.open(); openErr != nil {
+		return errors.Join(closeErr, err, openErr)
+	}
+	return errors.Join(closeErr, err)
+}
+
+func (r *RotatingFile) shiftBackups() error {
+	if r.maxBackups <= 0 {
+		if err := os.Remove(r.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	// Shift the backups along by one, dropping the oldest.
+	for i := r.maxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(r.backupPath(i), r.backupPath(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if err := os.Rename(r.path, r.backupPath(1)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (r *RotatingFile) backupPath(i int) string {
+	return fmt.Sprintf("%s.%d", r.path, i)
+}
@@ -0,0 +1,110 @@
+package events_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/armsnyder/a2s-exporter/internal/events"
+)
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	file, err := events.OpenRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each write of 6 bytes fills more than half the file, so every write after the first rotates.
+	for _, line := range []string{"aaaaa\n", "bbbbb\n", "ccccc\n", "ddddd\n"} {
+		if _, err := file.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The oldest file was dropped.
+	for name, want := range map[string]string{
+		"events.jsonl":   "ddddd\n",
+		"events.jsonl.1": "ccccc\n",
+		"events.jsonl.2": "bbbbb\n",
+	} {
+		got, err := os.ReadFile(filepath.Join(filepath.Dir(path), name))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("%s: wanted %q, got %q", name, want, got)
+		}
+	}
+
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected %s.3 not to exist", path)
+	}
+}
+
+func TestRotatingFile_Append(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	if err := os.WriteFile(path, []byte("aaaaa\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The existing size counts towards rotation.
+	file, err := events.OpenRotatingFile(path, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.Write([]byte("bbbbb\n")); err != nil {
+		t.Fatal(err)
+	}
+	_ = file.Close()
+
+	if got, _ := os.ReadFile(path + ".1"); string(got) != "aaaaa\n" {
+		t.Errorf("expected the existing content to be rotated, got %q", got)
+	}
+}
+
+func TestRotatingFile_RotateError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	file, err := events.OpenRotatingFile(path, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write([]byte("aaaaa\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	// A non-empty directory in place of the backup makes the rename fail.
+	if err := os.MkdirAll(filepath.Join(path+".1", "blocker"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := file.Write([]byte("bbbbb\n")); err == nil {
+		t.Error("expected an error when rotation fails")
+	}
+	if got, _ := os.ReadFile(path); string(got) != "aaaaa\nbbbbb\n" {
+		t.Errorf("expected the event to be written despite the failed rotation, got %q", got)
+	}
+
+	// Rotation is retried once the backup can be written.
+	if err := os.RemoveAll(path + ".1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.Write([]byte("ccccc\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "ccccc\n" {
+		t.Errorf("expected a new file after rotation, got %q", got)
+	}
+	if got, _ := os.ReadFile(path + ".1"); string(got) != "aaaaa\nbbbbb\n" {
+		t.Errorf("expected the previous content to be rotated, got %q", got)
+	}
+}
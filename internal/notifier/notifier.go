@@ -0,0 +1,374 @@
+// Package notifier posts events to webhooks, such as Discord and Slack.
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/armsnyder/a2s-exporter/internal/events"
+)
+
+// Kind is the format of the webhook payload.
+type Kind string
+
+const (
+	// Discord posts {"content": "<message>"}, with mentions disabled.
+	Discord Kind = "discord"
+	// Slack posts {"text": "<message>"}, with the control characters in the event fields escaped.
+	Slack Kind = "slack"
+	// JSON posts the event itself, or the rendered template if there is one. The event fields are escaped for use
+	// within JSON strings in the template.
+	JSON Kind = "json"
+)
+
+// queueSize is the number of events which may wait to be posted before further events are dropped.
+const queueSize = 100
+
+// requestTimeout is the timeout of a single webhook request.
+const requestTimeout = 10 * time.Second
+
+// Config configures a Notifier.
+type Config struct {
+	Name string `yaml:"name"`
+	Kind Kind   `yaml:"type"`
+	URL  string `yaml:"url"`
+	// Headers are added to each request, for example for authentication.
+	Headers map[string]string `yaml:"headers"`
+	// Template is a Go text/template rendered with the Event. It is the message for Discord and Slack, and the whole
+	// request body for JSON. Optional.
+	Template string `yaml:"template"`
+	// Debounce holds each event for this long before posting it, so that short-lived changes are not posted. If the
+	// opposite event arrives while an event is held, such as server_up for server_down, neither is posted. Optional.
+	Debounce time.Duration `yaml:"debounce"`
+	// Events selects which events are posted, overriding Template and Debounce per event. All events are posted if
+	// it is empty.
+	Events []EventConfig `yaml:"events"`
+}
+
+// EventConfig selects an event type for a Notifier.
+type EventConfig struct {
+	Type     events.Type    `yaml:"type"`
+	Template string         `yaml:"template"`
+	Debounce *time.Duration `yaml:"debounce"`
+}
+
+// defaultMessages are the messages posted to Discord and Slack when there is no template.
+var defaultMessages = map[events.Type]string{
+	events.PlayerJoin:    `{{ .Player }} joined {{ .ServerName }}`,
+	events.PlayerLeave:   `{{ .Player }} left {{ .ServerName }}`,
+	events.MapChange:     `{{ .ServerName }} changed map from {{ .PreviousMap }} to {{ .Map }}`,
+	events.ServerRestart: `{{ .ServerName }} restarted`,
+	events.ServerDown:    `{{ with .ServerName }}{{ . }}{{ else }}{{ .Target }}{{ end }} is down`,
+	events.ServerUp:      `{{ .ServerName }} is up`,
+	events.ServerFull:    `{{ .ServerName }} is full ({{ .Players }}/{{ .MaxPlayers }})`,
+}
+
+var defaultTemplates = func() map[events.Type]*template.Template {
+	templates := make(map[events.Type]*template.Template, len(defaultMessages))
+	for eventType, message := range defaultMessages {
+		templates[eventType] = template.Must(template.New(string(eventType)).Parse(message))
+	}
+	return templates
+}()
+
+// opposites are the pairs of events which cancel each other out when debounced.
+var opposites = map[events.Type]events.Type{
+	events.ServerDown:  events.ServerUp,
+	events.ServerUp:    events.ServerDown,
+	events.PlayerJoin:  events.PlayerLeave,
+	events.PlayerLeave: events.PlayerJoin,
+}
+
+// Notifier is an events.Sink which posts events to a webhook. Events are posted in the background, in order, except
+// that debounced events are posted once they have been held.
+type Notifier struct {
+	name     string
+	kind     Kind
+	url      string
+	headers  map[string]string
+	client   *http.Client
+	routes   map[events.Type]route
+	allTypes bool
+	fallback route
+	queue    chan events.Event
+
+	mu      sync.Mutex
+	pending map[pendingKey]*pendingEvent
+}
+
+type route struct {
+	template *template.Template
+	debounce time.Duration
+}
+
+// pendingKey identifies a held event. A repeat of the event while it is held replaces it.
+type pendingKey struct {
+	eventType events.Type
+	target    string
+	player    string
+	// connectedAt tells players apart when their names are omitted, in Unix nanoseconds.
+	connectedAt int64
+}
+
+func keyOf(event events.Event) pendingKey {
+	key := pendingKey{eventType: event.Type, target: event.Target, player: event.Player}
+	if event.Player == "" && event.ConnectedAt != nil {
+		key.connectedAt = event.ConnectedAt.UnixNano()
+	}
+	return key
+}
+
+type pendingEvent struct {
+	event events.Event
+	timer *time.Timer
+}
+
+// New validates the Config and starts a Notifier.
+func New(cfg Config) (*Notifier, error) {
+	switch cfg.Kind {
+	case Discord, Slack, JSON:
+	default:
+		return nil, fmt.Errorf("notifier %s: unknown type %q", cfg.Name, cfg.Kind)
+	}
+
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("notifier %s: url is required", cfg.Name)
+	}
+
+	fallback, err := parseTemplate(cfg.Name, "default", cfg.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &Notifier{
+		name:     cfg.Name,
+		kind:     cfg.Kind,
+		url:      cfg.URL,
+		headers:  cfg.Headers,
+		client:   &http.Client{Timeout: requestTimeout},
+		routes:   make(map[events.Type]route),
+		allTypes: len(cfg.Events) == 0,
+		fallback: route{template: fallback, debounce: cfg.Debounce},
+		queue:    make(chan events.Event, queueSize),
+		pending:  make(map[pendingKey]*pendingEvent),
+	}
+
+	for _, eventCfg := range cfg.Events {
+		if _, ok := defaultMessages[eventCfg.Type]; !ok {
+			return nil, fmt.Errorf("notifier %s: unknown event type %q", cfg.Name, eventCfg.Type)
+		}
+
+		r := n.fallback
+		if eventCfg.Template != "" {
+			if r.template, err = parseTemplate(cfg.Name, string(eventCfg.Type), eventCfg.Template); err != nil {
+				return nil, err
+			}
+		}
+		if eventCfg.Debounce != nil {
+			r.debounce = *eventCfg.Debounce
+		}
+		n.routes[eventCfg.Type] = r
+	}
+
+	go n.run()
+
+	return n, nil
+}
+
+func parseTemplate(notifierName, name, text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("notifier %s: could not parse %s template: %w", notifierName, name, err)
+	}
+	return tmpl, nil
+}
+
+// Emit queues the event to be posted, unless it is filtered, or holds it if it is debounced. It does not block.
+func (n *Notifier) Emit(event events.Event) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	// An event which reverses a held event cancels it, and is not posted either, even if it is filtered.
+	if opposite, ok := opposites[event.Type]; ok {
+		key := keyOf(event)
+		key.eventType = opposite
+		if held, ok := n.pending[key]; ok {
+			held.timer.Stop()
+			delete(n.pending, key)
+			return
+		}
+	}
+
+	r, ok := n.routes[event.Type]
+	if !ok {
+		if !n.allTypes {
+			return
+		}
+		r = n.fallback
+	}
+
+	if r.debounce <= 0 {
+		n.enqueue(event)
+		return
+	}
+
+	key := keyOf(event)
+	if held, ok := n.pending[key]; ok {
+		held.event = event
+		return
+	}
+
+	held := &pendingEvent{event: event}
+	held.timer = time.AfterFunc(r.debounce, func() {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		// The event may have been cancelled after the timer fired.
+		if n.pending[key] != held {
+			return
+		}
+		delete(n.pending, key)
+		n.enqueue(held.event)
+	})
+	n.pending[key] = held
+}
+
+func (n *Notifier) enqueue(event events.Event) {
+	select {
+	case n.queue <- event:
+	default:
+		fmt.Fprintf(os.Stderr, "Notifier %s: queue is full, dropping %s event\n", n.name, event.Type)
+	}
+}
+
+func (n *Notifier) run() {
+	for event := range n.queue {
+		if err := n.post(event); err != nil {
+			fmt.Fprintf(os.Stderr, "Notifier %s: could not post %s event: %v\n", n.name, event.Type, err)
+		}
+	}
+}
+
+func (n *Notifier) post(event events.Event) error {
+	body, err := n.payload(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range n.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
+
+// payload renders the request body for an event.
+func (n *Notifier) payload(event events.Event) ([]byte, error) {
+	tmpl := n.fallback.template
+	if r, ok := n.routes[event.Type]; ok {
+		tmpl = r.template
+	}
+
+	if n.kind == JSON {
+		if tmpl == nil {
+			return json.Marshal(event)
+		}
+		return render(tmpl, escapeJSON(event))
+	}
+
+	if tmpl == nil {
+		tmpl = defaultTemplates[event.Type]
+	}
+
+	// Player and server names are untrusted, so they must not be able to mention everyone in the channel.
+	if n.kind == Slack {
+		event = escapeSlack(event)
+	}
+
+	message, err := render(tmpl, event)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimSpace(string(message))
+
+	if n.kind == Slack {
+		return json.Marshal(slackMessage{Text: text})
+	}
+	return json.Marshal(discordMessage{Content: text, AllowedMentions: discordAllowedMentions{Parse: []string{}}})
+}
+
+type discordMessage struct {
+	Content         string                 `json:"content"`
+	AllowedMentions discordAllowedMentions `json:"allowed_mentions"`
+}
+
+type discordAllowedMentions struct {
+	// Parse is the types of mentions which are allowed. It is empty to allow none.
+	Parse []string `json:"parse"`
+}
+
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// escapeJSON escapes the string fields of an event for use within JSON strings, so that names containing quotes cannot
+// break the request body or add fields to it.
+func escapeJSON(event events.Event) events.Event {
+	event.Target = escapeJSONString(event.Target)
+	event.ServerName = escapeJSONString(event.ServerName)
+	event.Player = escapeJSONString(event.Player)
+	event.Map = escapeJSONString(event.Map)
+	event.PreviousMap = escapeJSONString(event.PreviousMap)
+	return event
+}
+
+func escapeJSONString(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted[1 : len(quoted)-1])
+}
+
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// escapeSlack escapes the string fields of an event, so that Slack shows them as text rather than as mentions or
+// links.
+func escapeSlack(event events.Event) events.Event {
+	event.Target = slackEscaper.Replace(event.Target)
+	event.ServerName = slackEscaper.Replace(event.ServerName)
+	event.Player = slackEscaper.Replace(event.Player)
+	event.Map = slackEscaper.Replace(event.Map)
+	event.PreviousMap = slackEscaper.Replace(event.PreviousMap)
+	return event
+}
+
+func render(tmpl *template.Template, event events.Event) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, event); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
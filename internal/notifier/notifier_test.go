@@ -0,0 +1,236 @@
+package notifier_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/armsnyder/a2s-exporter/internal/events"
+	"github.com/armsnyder/a2s-exporter/internal/notifier"
+)
+
+func TestNotifier(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		config notifier.Config
+		emit   []events.Event
+		want   []string
+	}{
+		{
+			name:   "discord default message",
+			config: notifier.Config{Kind: notifier.Discord},
+			emit:   []events.Event{{Time: start, Type: events.PlayerJoin, ServerName: "foo", Player: "jon"}},
+			want:   []string{`{"content":"jon joined foo","allowed_mentions":{"parse":[]}}`},
+		},
+		{
+			name:   "slack template",
+			config: notifier.Config{Kind: notifier.Slack, Template: `{{ .Type }} on {{ .Target }}`},
+			emit:   []events.Event{{Time: start, Type: events.ServerDown, Target: "host:1"}},
+			want:   []string{`{"text":"server_down on host:1"}`},
+		},
+		{
+			name:   "discord hostile name",
+			config: notifier.Config{Kind: notifier.Discord},
+			emit:   []events.Event{{Time: start, Type: events.PlayerJoin, ServerName: "foo", Player: "@everyone"}},
+			want:   []string{`{"content":"@everyone joined foo","allowed_mentions":{"parse":[]}}`},
+		},
+		{
+			name:   "slack hostile name",
+			config: notifier.Config{Kind: notifier.Slack, Template: "<!here> {{ .Player }} joined {{ .ServerName }}"},
+			emit:   []events.Event{{Time: start, Type: events.PlayerJoin, ServerName: "a & b", Player: "<!channel>"}},
+			want:   []string{`{"text":"\u003c!here\u003e \u0026lt;!channel\u0026gt; joined a \u0026amp; b"}`},
+		},
+		{
+			name:   "json event",
+			config: notifier.Config{Kind: notifier.JSON},
+			emit:   []events.Event{{Time: start, Type: events.ServerUp, Target: "host:1"}},
+			want:   []string{`{"time":"2024-01-02T03:04:05Z","type":"server_up","target":"host:1"}`},
+		},
+		{
+			name:   "json template hostile name",
+			config: notifier.Config{Kind: notifier.JSON, Template: `{"who": "{{ .Player }}"}`},
+			emit:   []events.Event{{Time: start, Type: events.PlayerJoin, Player: `jon", "admin": "true`}},
+			want:   []string{`{"who": "jon\", \"admin\": \"true"}`},
+		},
+		{
+			name: "event filter and per-event template",
+			config: notifier.Config{
+				Kind:   notifier.Discord,
+				Events: []notifier.EventConfig{{Type: events.MapChange, Template: "now playing {{ .Map }}"}},
+			},
+			emit: []events.Event{
+				{Time: start, Type: events.PlayerJoin, Player: "jon"},
+				{Time: start, Type: events.MapChange, Map: "de_dust2"},
+			},
+			want: []string{`{"content":"now playing de_dust2","allowed_mentions":{"parse":[]}}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bodies := make(chan string, 10)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				bodies <- string(b)
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer srv.Close()
+
+			tt.config.URL = srv.URL
+			n, err := notifier.New(tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, event := range tt.emit {
+				n.Emit(event)
+			}
+
+			for _, want := range tt.want {
+				select {
+				case got := <-bodies:
+					if got != want {
+						t.Errorf("wanted body %s, got %s", want, got)
+					}
+				case <-time.After(5 * time.Second):
+					t.Fatalf("timed out waiting for body %s", want)
+				}
+			}
+
+			select {
+			case got := <-bodies:
+				t.Errorf("unexpected body %s", got)
+			case <-time.After(50 * time.Millisecond):
+			}
+		})
+	}
+}
+
+func TestNotifier_Debounce(t *testing.T) {
+	bodies := make(chan string, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies <- string(b)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	debounce := 50 * time.Millisecond
+	noDebounce := time.Duration(0)
+	n, err := notifier.New(notifier.Config{
+		Kind:     notifier.Discord,
+		URL:      srv.URL,
+		Template: "{{ .Type }} {{ .Player }}",
+		Debounce: debounce,
+		Events: []notifier.EventConfig{
+			{Type: events.ServerDown},
+			{Type: events.ServerUp},
+			{Type: events.PlayerJoin, Debounce: &noDebounce},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := func(want string, minDelay time.Duration) {
+		t.Helper()
+		start := time.Now()
+		select {
+		case got := <-bodies:
+			if want := `{"content":"` + want + `","allowed_mentions":{"parse":[]}}`; got != want {
+				t.Errorf("wanted body %s, got %s", want, got)
+			}
+			if elapsed := time.Since(start); elapsed < minDelay {
+				t.Errorf("%s: expected the post to be held for %v, but it was posted after %v", want, minDelay, elapsed)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for body %s", want)
+		}
+	}
+
+	// A short outage is not posted at all.
+	n.Emit(events.Event{Type: events.ServerDown, Target: "host:1"})
+	n.Emit(events.Event{Type: events.ServerUp, Target: "host:1"})
+
+	// Events without debounce are posted right away.
+	n.Emit(events.Event{Type: events.PlayerJoin, Target: "host:1", Player: "jon"})
+	expect("player_join jon", 0)
+
+	// A lasting change is posted once it has been held.
+	n.Emit(events.Event{Type: events.ServerDown, Target: "host:1"})
+	expect("server_down", debounce/2)
+	n.Emit(events.Event{Type: events.ServerUp, Target: "host:1"})
+	expect("server_up", debounce/2)
+
+	select {
+	case got := <-bodies:
+		t.Errorf("unexpected body %s", got)
+	case <-time.After(2 * debounce):
+	}
+}
+
+func TestNotifier_DebounceOmittedNames(t *testing.T) {
+	bodies := make(chan string, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies <- string(b)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	n, err := notifier.New(notifier.Config{
+		Kind:     notifier.Discord,
+		URL:      srv.URL,
+		Template: "{{ .Type }}",
+		Debounce: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without names, players are told apart by when they connected, so one player leaving does not cancel another
+	// player's join, and two joins are not merged.
+	first := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	second := first.Add(time.Minute)
+	third := first.Add(2 * time.Minute)
+	n.Emit(events.Event{Type: events.PlayerJoin, Target: "host:1", ConnectedAt: &second})
+	n.Emit(events.Event{Type: events.PlayerJoin, Target: "host:1", ConnectedAt: &third})
+	n.Emit(events.Event{Type: events.PlayerLeave, Target: "host:1", ConnectedAt: &first})
+
+	counts := make(map[string]int)
+	for i := 0; i < 3; i++ {
+		select {
+		case got := <-bodies:
+			counts[got]++
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for body %d", i+1)
+		}
+	}
+	if counts[`{"content":"player_join","allowed_mentions":{"parse":[]}}`] != 2 || counts[`{"content":"player_leave","allowed_mentions":{"parse":[]}}`] != 1 {
+		t.Errorf("expected two joins and a leave, got %v", counts)
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config notifier.Config
+	}{
+		{name: "unknown type", config: notifier.Config{Kind: "email", URL: "http://example.com"}},
+		{name: "missing url", config: notifier.Config{Kind: notifier.Discord}},
+		{name: "bad template", config: notifier.Config{Kind: notifier.Discord, URL: "http://example.com", Template: "{{"}},
+		{name: "unknown event", config: notifier.Config{Kind: notifier.Discord, URL: "http://example.com", Events: []notifier.EventConfig{{Type: "nope"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := notifier.New(tt.config); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
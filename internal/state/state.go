@@ -0,0 +1,114 @@
+// Package state persists the derived state of the collectors, such as player sessions and rolling statistics, so that
+// it survives a restart of the exporter.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// fileVersion is the version of the state file layout. The state of each target is versioned separately by the
+// target itself.
+const fileVersion = 1
+
+// Target is a collector whose state is persisted.
+type Target interface {
+	Addr() string
+	SaveState() ([]byte, error)
+	RestoreState(data []byte) error
+}
+
+// file is the layout of the state file.
+type file struct {
+	Version int                        `json:"version"`
+	Targets map[string]json.RawMessage `json:"targets"`
+}
+
+// Store saves and restores the state of targets in a JSON file, keyed by target address.
+type Store struct {
+	path string
+	// mu serializes saves, so that a periodic save and the save on shutdown do not interleave.
+	mu sync.Mutex
+}
+
+// New returns a Store which uses the file at path.
+func New(path string) *Store {
+	return &Store{path: path}
+}
+
+// Restore restores the state of each target found in the state file. A missing file is not an error, since there is
+// no state on the first run. A target whose state cannot be restored is skipped, and starts afresh.
+func (s *Store) Restore(targets []Target) error {
+	b, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var f file
+	if err := json.Unmarshal(b, &f); err != nil {
+		return fmt.Errorf("could not parse %s: %w", s.path, err)
+	}
+	if f.Version != fileVersion {
+		return fmt.Errorf("%s has unsupported version %d", s.path, f.Version)
+	}
+
+	var errs []error
+	for _, target := range targets {
+		data, ok := f.Targets[target.Addr()]
+		if !ok {
+			continue
+		}
+		if err := target.RestoreState(data); err != nil {
+			errs = append(errs, fmt.Errorf("could not restore state of %s: %w", target.Addr(), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Save writes the state of all targets to the state file. The file is replaced atomically, so that a crash during the
+// save does not leave a truncated file behind.
+func (s *Store) Save(targets []Target) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f := file{
+		Version: fileVersion,
+		Targets: make(map[string]json.RawMessage, len(targets)),
+	}
+	for _, target := range targets {
+		data, err := target.SaveState()
+		if err != nil {
+			return fmt.Errorf("could not save state of %s: %w", target.Addr(), err)
+		}
+		f.Targets[target.Addr()] = data
+	}
+
+	b, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}
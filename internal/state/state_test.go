@@ -0,0 +1,111 @@
+package state_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/armsnyder/a2s-exporter/internal/state"
+)
+
+type testTarget struct {
+	addr       string
+	data       string
+	restoreErr error
+}
+
+func (t *testTarget) Addr() string {
+	return t.addr
+}
+
+func (t *testTarget) SaveState() ([]byte, error) {
+	return []byte(t.data), nil
+}
+
+func (t *testTarget) RestoreState(data []byte) error {
+	if t.restoreErr != nil {
+		return t.restoreErr
+	}
+	t.data = string(data)
+	return nil
+}
+
+func TestStore_SaveRestore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	saved := []state.Target{
+		&testTarget{addr: "a:27015", data: `{"a":1}`},
+		&testTarget{addr: "b:27015", data: `{"b":2}`},
+	}
+	if err := state.New(path).Save(saved); err != nil {
+		t.Fatal(err)
+	}
+
+	a := &testTarget{addr: "a:27015"}
+	c := &testTarget{addr: "c:27015"}
+	if err := state.New(path).Restore([]state.Target{a, c}); err != nil {
+		t.Fatal(err)
+	}
+
+	if a.data != `{"a":1}` {
+		t.Errorf("wanted restored state %s, got %s", `{"a":1}`, a.data)
+	}
+	if c.data != "" {
+		t.Errorf("wanted no state for an unknown target, got %s", c.data)
+	}
+
+	// Only the state file itself is left behind.
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("wanted 1 file, got %d", len(entries))
+	}
+}
+
+func TestStore_Restore_Missing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	if err := state.New(path).Restore([]state.Target{&testTarget{addr: "a:27015"}}); err != nil {
+		t.Errorf("wanted no error for a missing file, got %v", err)
+	}
+}
+
+func TestStore_Restore_UnsupportedVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte(`{"version":999,"targets":{"a:27015":{}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	target := &testTarget{addr: "a:27015"}
+	if err := state.New(path).Restore([]state.Target{target}); err == nil {
+		t.Error("wanted an error for an unsupported version")
+	}
+	if target.data != "" {
+		t.Errorf("wanted no state to be restored, got %s", target.data)
+	}
+}
+
+func TestStore_Restore_TargetError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	saved := []state.Target{
+		&testTarget{addr: "a:27015", data: `{"a":1}`},
+		&testTarget{addr: "b:27015", data: `{"b":2}`},
+	}
+	if err := state.New(path).Save(saved); err != nil {
+		t.Fatal(err)
+	}
+
+	// A target which fails to restore does not prevent the others from being restored.
+	a := &testTarget{addr: "a:27015", restoreErr: errors.New("bad")}
+	b := &testTarget{addr: "b:27015"}
+	if err := state.New(path).Restore([]state.Target{a, b}); err == nil {
+		t.Error("wanted an error")
+	}
+	if b.data != `{"b":2}` {
+		t.Errorf("wanted restored state %s, got %s", `{"b":2}`, b.data)
+	}
+}
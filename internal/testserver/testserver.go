@@ -6,6 +6,8 @@ import (
 	"io"
 	"math"
 	"net"
+	"sort"
+	"sync"
 
 	"github.com/rumblefrog/go-a2s"
 )
@@ -17,6 +19,16 @@ var challenge = uint32(1876276358)
 type TestServer struct {
 	ServerInfo *a2s.ServerInfo
 	PlayerInfo *a2s.PlayerInfo
+	Rules      *a2s.RulesInfo
+
+	mu sync.Mutex
+}
+
+// Update calls fn while no request is being handled, so that the fields may be safely changed while serving.
+func (t *TestServer) Update(fn func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fn()
 }
 
 // Serve runs the A2S server.
@@ -32,39 +44,60 @@ func (t *TestServer) Serve(conn net.PacketConn) error {
 		}
 
 		// Handle the request packet.
-
-		// Validate packet header.
-		if binary.LittleEndian.Uint32(buf[:5]) != math.MaxUint32 {
-			continue
+		if err := t.handle(conn, remoteAddr, buf[:]); err != nil {
+			return err
 		}
+	}
+}
 
-		queryType := buf[4]
-		out := &udpWriter{conn: conn, addr: remoteAddr}
+// handle handles a single request packet.
+func (t *TestServer) handle(conn net.PacketConn, remoteAddr net.Addr, buf []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 
-		switch queryType {
-		// Server info query (no challenge).
-		case 'T':
-			err = t.writeServerInfo(out)
+	// Validate packet header.
+	if binary.LittleEndian.Uint32(buf[:5]) != math.MaxUint32 {
+		return nil
+	}
 
-			// Player info query.
-		case 'U':
-			gotChallenge := binary.LittleEndian.Uint32(buf[5:9])
+	queryType := buf[4]
+	out := &udpWriter{conn: conn, addr: remoteAddr}
 
-			switch gotChallenge {
-			// No challenge.
-			case math.MaxUint32:
-				err = t.writeChallenge(out)
+	switch queryType {
+	// Server info query (no challenge).
+	case 'T':
+		return t.writeServerInfo(out)
 
-			// Correct challenge.
-			case challenge:
-				err = t.writePlayerInfo(out)
-			}
+		// Player info query.
+	case 'U':
+		gotChallenge := binary.LittleEndian.Uint32(buf[5:9])
+
+		switch gotChallenge {
+		// No challenge.
+		case math.MaxUint32:
+			return t.writeChallenge(out)
+
+		// Correct challenge.
+		case challenge:
+			return t.writePlayerInfo(out)
 		}
 
-		if err != nil {
-			return err
+		// Rules query.
+	case 'V':
+		gotChallenge := binary.LittleEndian.Uint32(buf[5:9])
+
+		switch gotChallenge {
+		// No challenge.
+		case math.MaxUint32:
+			return t.writeChallenge(out)
+
+		// Correct challenge.
+		case challenge:
+			return t.writeRules(out)
 		}
 	}
+
+	return nil
 }
 
 func (t *TestServer) writeChallenge(out io.Writer) error {
@@ -200,6 +233,39 @@ func (t *TestServer) writePlayerInfo(out io.Writer) error {
 	return err
 }
 
+func (t *TestServer) writeRules(out io.Writer) error {
+	info := t.Rules
+	if info == nil {
+		info = &a2s.RulesInfo{}
+	}
+
+	// Response packet buffer.
+	buf := &packetBuffer{}
+
+	// Header.
+	buf.WriteUInt32(math.MaxUint32)
+	buf.WriteByte('E')
+
+	// Payload.
+	buf.WriteUInt16(uint16(len(info.Rules)))
+
+	// Sort the rules so that the response is deterministic.
+	names := make([]string, 0, len(info.Rules))
+	for name := range info.Rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		buf.WriteCString(name)
+		buf.WriteCString(info.Rules[name])
+	}
+
+	// Write the packet out.
+	_, err := io.Copy(out, buf)
+	return err
+}
+
 // packetBuffer extends bytes.Buffer to add more data types used by A2S.
 type packetBuffer struct {
 	bytes.Buffer
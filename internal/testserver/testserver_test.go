@@ -15,6 +15,7 @@ func TestTestServer_Serve(t *testing.T) {
 	type fields struct {
 		ServerInfo *a2s.ServerInfo
 		PlayerInfo *a2s.PlayerInfo
+		Rules      *a2s.RulesInfo
 	}
 
 	tests := []struct {
@@ -139,6 +140,18 @@ func TestTestServer_Serve(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "rules",
+			fields: fields{
+				Rules: &a2s.RulesInfo{
+					Count: 2,
+					Rules: map[string]string{
+						"mp_timelimit": "30",
+						"sv_cheats":    "0",
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -154,6 +167,7 @@ func TestTestServer_Serve(t *testing.T) {
 			srv := &testserver.TestServer{
 				ServerInfo: tt.fields.ServerInfo,
 				PlayerInfo: tt.fields.PlayerInfo,
+				Rules:      tt.fields.Rules,
 			}
 
 			// Serve in background.
@@ -192,6 +206,15 @@ func TestTestServer_Serve(t *testing.T) {
 				testJSONCopy(t, want, tt.fields.PlayerInfo)
 				testAssertJSONEqual(t, want, playerInfo)
 			}
+
+			// Query the rules and check that they match how the TestServer was initialized.
+			if tt.fields.Rules != nil {
+				if rules, err := client.QueryRules(); err != nil {
+					t.Errorf("Unexpected error while querying rules: %v", err)
+				} else {
+					testAssertJSONEqual(t, tt.fields.Rules, rules)
+				}
+			}
 		})
 	}
 }
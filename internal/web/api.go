@@ -0,0 +1,143 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rumblefrog/go-a2s"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+)
+
+// APIPath is the path of the server list in the JSON API. A single server is served at APIPath/{target}.
+const APIPath = "/api/v1/servers"
+
+// APIOpts configures the JSON API.
+type APIOpts struct {
+	Targets []*collector.Collector
+	// CORSOrigins are the origins allowed to make cross-origin requests. "*" allows any origin.
+	CORSOrigins []string
+}
+
+type apiServerList struct {
+	Servers []apiServer `json:"servers"`
+}
+
+type apiServer struct {
+	Target     string            `json:"target"`
+	Up         bool              `json:"up"`
+	LastQuery  *time.Time        `json:"last_query,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	ServerInfo *a2s.ServerInfo   `json:"server_info"`
+	Players    []*a2s.Player     `json:"players"`
+	Rules      map[string]string `json:"rules"`
+}
+
+// APIHandler serves the latest query results of each target as JSON. These are the same results used for the most
+// recent metrics.
+func APIHandler(opts APIOpts) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		setCORSHeaders(w, r, opts.CORSOrigins)
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodOptions:
+			w.WriteHeader(http.StatusNoContent)
+			return
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		name := strings.Trim(strings.TrimPrefix(r.URL.Path, APIPath), "/")
+
+		// Server list.
+		if name == "" {
+			list := apiServerList{Servers: make([]apiServer, 0, len(opts.Targets))}
+			for _, target := range opts.Targets {
+				list.Servers = append(list.Servers, newAPIServer(target))
+			}
+			writeJSON(w, http.StatusOK, list)
+			return
+		}
+
+		// Single server.
+		if target := findTarget(opts.Targets, name); target != nil {
+			writeJSON(w, http.StatusOK, newAPIServer(target))
+			return
+		}
+
+		writeJSON(w, http.StatusNotFound, struct {
+			Error string `json:"error"`
+		}{"unknown target " + name})
+	})
+}
+
+func newAPIServer(target *collector.Collector) apiServer {
+	status := target.Status()
+
+	server := apiServer{
+		Target:     target.Addr(),
+		Up:         status.ServerUp,
+		ServerInfo: status.ServerInfo,
+		Players:    []*a2s.Player{},
+		Rules:      map[string]string{},
+	}
+
+	if !status.Time.IsZero() {
+		server.LastQuery = &status.Time
+	}
+
+	if status.Err != nil {
+		server.Error = status.Err.Error()
+	}
+
+	if status.PlayerInfo != nil {
+		server.Players = status.PlayerInfo.Players
+	}
+
+	if status.Rules != nil {
+		server.Rules = status.Rules.Rules
+	}
+
+	return server
+}
+
+// findTarget returns the target with the given name, or nil if there is none.
+func findTarget(targets []*collector.Collector, name string) *collector.Collector {
+	for _, target := range targets {
+		if target.Addr() == name {
+			return target
+		}
+	}
+	return nil
+}
+
+// setCORSHeaders allows the request's origin if it is one of the allowed origins.
+func setCORSHeaders(w http.ResponseWriter, r *http.Request, allowedOrigins []string) {
+	origin := r.Header.Get("Origin")
+
+	for _, allowed := range allowedOrigins {
+		if allowed == "*" {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			break
+		}
+		if origin != "" && allowed == origin {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Add("Vary", "Origin")
+			break
+		}
+	}
+
+	if w.Header().Get("Access-Control-Allow-Origin") != "" {
+		w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS")
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,106 @@
+package web_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rumblefrog/go-a2s"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+	"github.com/armsnyder/a2s-exporter/internal/testserver"
+	"github.com/armsnyder/a2s-exporter/internal/web"
+)
+
+func TestAPIHandler(t *testing.T) {
+	addr := testServe(t, &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo", Map: "de_dust2", Players: 1, MaxPlayers: 8},
+		PlayerInfo: &a2s.PlayerInfo{Count: 1, Players: []*a2s.Player{{Name: "jon", Score: 3, Duration: 60}}},
+		Rules:      &a2s.RulesInfo{Count: 1, Rules: map[string]string{"mp_timelimit": "30"}},
+	})
+	c := collector.New("", addr, collector.Options{QueryRules: true})
+	defer c.Close()
+	c.Refresh()
+
+	handler := web.APIHandler(web.APIOpts{
+		Targets:     []*collector.Collector{c},
+		CORSOrigins: []string{"https://example.com"},
+	})
+
+	type server struct {
+		Target     string            `json:"target"`
+		Up         bool              `json:"up"`
+		ServerInfo *a2s.ServerInfo   `json:"server_info"`
+		Players    []*a2s.Player     `json:"players"`
+		Rules      map[string]string `json:"rules"`
+	}
+
+	assertServer := func(t *testing.T, got server) {
+		t.Helper()
+		if got.Target != addr || !got.Up {
+			t.Errorf("unexpected target %q or up %t", got.Target, got.Up)
+		}
+		if got.ServerInfo == nil || got.ServerInfo.Map != "de_dust2" {
+			t.Errorf("unexpected server info %+v", got.ServerInfo)
+		}
+		if len(got.Players) != 1 || got.Players[0].Name != "jon" {
+			t.Errorf("unexpected players %+v", got.Players)
+		}
+		if got.Rules["mp_timelimit"] != "30" {
+			t.Errorf("unexpected rules %+v", got.Rules)
+		}
+	}
+
+	t.Run("list", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, web.APIPath, nil)
+		req.Header.Set("Origin", "https://example.com")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d but got %d", http.StatusOK, rec.Code)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://example.com" {
+			t.Errorf("unexpected Access-Control-Allow-Origin %q", got)
+		}
+
+		var list struct {
+			Servers []server `json:"servers"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&list); err != nil {
+			t.Fatal(err)
+		}
+		if len(list.Servers) != 1 {
+			t.Fatalf("expected 1 server but got %d", len(list.Servers))
+		}
+		assertServer(t, list.Servers[0])
+	})
+
+	t.Run("single", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, web.APIPath+"/"+addr, nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d but got %d", http.StatusOK, rec.Code)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("unexpected Access-Control-Allow-Origin %q for request without origin", got)
+		}
+
+		var got server
+		if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		assertServer(t, got)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, web.APIPath+"/nope", nil))
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status %d but got %d", http.StatusNotFound, rec.Code)
+		}
+	})
+}
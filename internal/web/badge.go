@@ -0,0 +1,109 @@
+package web
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+)
+
+// BadgePath is the path prefix of the badges. A badge is served at BadgePath/{target}.svg.
+const BadgePath = "/badge/"
+
+// badgeMaxAge is how long, in seconds, clients and proxies may cache a badge.
+const badgeMaxAge = 60
+
+const (
+	badgeColorUp      = "#4c1"
+	badgeColorDown    = "#e05d44"
+	badgeColorUnknown = "#9f9f9f"
+)
+
+// badgeTemplate is a shields.io-style flat badge. The arguments are the total width, label width, message width,
+// message color, label text center, message text center, label and message.
+const badgeTemplate = `<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[7]s: %[8]s">
+<title>%[7]s: %[8]s</title>
+<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)"><rect width="%[2]d" height="20" fill="#555"/><rect x="%[2]d" width="%[3]d" height="20" fill="%[4]s"/><rect width="%[1]d" height="20" fill="url(#s)"/></g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="%[5]d" y="15" fill="#010101" fill-opacity=".3">%[7]s</text><text x="%[5]d" y="14">%[7]s</text>
+<text x="%[6]d" y="15" fill="#010101" fill-opacity=".3">%[8]s</text><text x="%[6]d" y="14">%[8]s</text>
+</g>
+</svg>
+`
+
+// BadgeHandler serves an SVG status badge for each target, showing whether the server is up and its player count.
+// The badge label defaults to the server name, and may be overridden with the "label" query parameter.
+func BadgeHandler(targets []*collector.Collector) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, BadgePath)
+		if !strings.HasSuffix(name, ".svg") {
+			http.NotFound(w, r)
+			return
+		}
+		name = strings.TrimSuffix(name, ".svg")
+
+		target := findTarget(targets, name)
+		if target == nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		status := target.Status()
+
+		label := r.URL.Query().Get("label")
+		if label == "" {
+			label = status.ServerName
+		}
+		if label == "" {
+			label = "server"
+		}
+
+		message, color := "unknown", badgeColorUnknown
+		switch {
+		case status.Time.IsZero():
+		case status.ServerUp && status.ServerInfo != nil:
+			message = fmt.Sprintf("online %d/%d", status.ServerInfo.Players, status.ServerInfo.MaxPlayers)
+			color = badgeColorUp
+		default:
+			message, color = "offline", badgeColorDown
+		}
+
+		w.Header().Set("Content-Type", "image/svg+xml; charset=utf-8")
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", badgeMaxAge))
+		if !status.Time.IsZero() {
+			w.Header().Set("Last-Modified", status.Time.UTC().Format(http.TimeFormat))
+		}
+
+		_, _ = fmt.Fprint(w, renderBadge(label, message, color))
+	})
+}
+
+func renderBadge(label, message, color string) string {
+	labelWidth := badgeTextWidth(label)
+	messageWidth := badgeTextWidth(message)
+
+	return fmt.Sprintf(badgeTemplate,
+		labelWidth+messageWidth,
+		labelWidth,
+		messageWidth,
+		color,
+		labelWidth/2,
+		labelWidth+messageWidth/2,
+		html.EscapeString(label),
+		html.EscapeString(message),
+	)
+}
+
+// badgeTextWidth approximates the rendered width in pixels of a text section of the badge, including padding.
+func badgeTextWidth(text string) int {
+	const (
+		charWidth = 7
+		padding   = 10
+	)
+	return utf8.RuneCountInString(text)*charWidth + padding
+}
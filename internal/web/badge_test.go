@@ -0,0 +1,89 @@
+package web_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rumblefrog/go-a2s"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+	"github.com/armsnyder/a2s-exporter/internal/testserver"
+	"github.com/armsnyder/a2s-exporter/internal/web"
+)
+
+func TestBadgeHandler(t *testing.T) {
+	addr := testServe(t, &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo <bar>", Players: 3, MaxPlayers: 10},
+	})
+	up := collector.New("", addr, collector.Options{ExcludePlayerMetrics: true})
+	defer up.Close()
+	up.Refresh()
+
+	pending := collector.New("", "127.0.0.1:1", collector.Options{})
+
+	handler := web.BadgeHandler([]*collector.Collector{up, pending})
+
+	tests := []struct {
+		name     string
+		path     string
+		wantCode int
+		want     []string
+	}{
+		{
+			name:     "up",
+			path:     web.BadgePath + addr + ".svg",
+			wantCode: http.StatusOK,
+			want:     []string{"foo &lt;bar&gt;", "online 3/10", "#4c1"},
+		},
+		{
+			name:     "label override",
+			path:     web.BadgePath + addr + ".svg?label=my+server",
+			wantCode: http.StatusOK,
+			want:     []string{"my server", "online 3/10"},
+		},
+		{
+			name:     "not queried yet",
+			path:     web.BadgePath + "127.0.0.1:1.svg",
+			wantCode: http.StatusOK,
+			want:     []string{"server", "unknown"},
+		},
+		{
+			name:     "unknown target",
+			path:     web.BadgePath + "nope.svg",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "missing extension",
+			path:     web.BadgePath + addr,
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("expected status %d but got %d", tt.wantCode, rec.Code)
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "image/svg+xml") {
+				t.Errorf("unexpected Content-Type %q", got)
+			}
+			if got := rec.Header().Get("Cache-Control"); !strings.Contains(got, "max-age=") {
+				t.Errorf("unexpected Cache-Control %q", got)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(rec.Body.String(), want) {
+					t.Errorf("expected badge to contain %q but got:\n%s", want, rec.Body)
+				}
+			}
+		})
+	}
+}
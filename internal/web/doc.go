@@ -0,0 +1,4 @@
+// Package web serves the HTTP endpoints of the exporter. Apart from the metrics and probe endpoints, the handlers
+// serve the outcome of the most recent query of each target and never query the A2S servers themselves, so they
+// answer promptly even while a server is slow to respond.
+package web
@@ -0,0 +1,38 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+)
+
+// HealthzHandler reports that the process is alive.
+func HealthzHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprintln(w, "ok")
+	})
+}
+
+// ReadyzHandler reports whether the exporter is ready to serve metrics, which is once every configured target has
+// completed at least one query.
+func ReadyzHandler(targets []*collector.Collector) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		var pending []string
+
+		for _, target := range targets {
+			if target.Status().Time.IsZero() {
+				pending = append(pending, target.Addr())
+			}
+		}
+
+		if len(pending) > 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = fmt.Fprintln(w, "waiting for first query of", strings.Join(pending, ", "))
+			return
+		}
+
+		_, _ = fmt.Fprintln(w, "ok")
+	})
+}
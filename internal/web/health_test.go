@@ -0,0 +1,130 @@
+package web_test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rumblefrog/go-a2s"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+	"github.com/armsnyder/a2s-exporter/internal/testserver"
+	"github.com/armsnyder/a2s-exporter/internal/web"
+)
+
+func TestHealthzHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	web.HealthzHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status %d but got %d", http.StatusOK, rec.Code)
+	}
+}
+
+func TestReadyzHandler(t *testing.T) {
+	addr := testServe(t, &testserver.TestServer{ServerInfo: &a2s.ServerInfo{Name: "foo"}})
+	c := collector.New("", addr, collector.Options{})
+	defer c.Close()
+	handler := web.ReadyzHandler([]*collector.Collector{c})
+
+	// Not ready before the first query.
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d before first query but got %d", http.StatusServiceUnavailable, rec.Code)
+	}
+
+	c.Refresh()
+
+	// Ready after the first query.
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status %d after first query but got %d", http.StatusOK, rec.Code)
+	}
+}
+
+func TestReadyzHandler_QueryInProgress(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	stalling := &stallingConn{PacketConn: conn, stalled: make(chan struct{}, 1)}
+	go func() {
+		_ = (&testserver.TestServer{ServerInfo: &a2s.ServerInfo{Name: "foo"}}).Serve(stalling)
+	}()
+
+	c := collector.New("", conn.LocalAddr().String(), collector.Options{
+		ClientOptions: []func(*a2s.Client) error{a2s.TimeoutOption(2 * time.Second)},
+	})
+	defer c.Close()
+	handler := web.ReadyzHandler([]*collector.Collector{c})
+
+	c.Refresh()
+
+	// The server stops answering, so the next query is blocked until it times out.
+	stalling.stall.Store(true)
+	refreshed := make(chan struct{})
+	go func() {
+		c.Refresh()
+		close(refreshed)
+	}()
+	defer func() { <-refreshed }()
+	<-stalling.stalled
+
+	served := make(chan int)
+	go func() {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		served <- rec.Code
+	}()
+
+	select {
+	case code := <-served:
+		if code != http.StatusOK {
+			t.Errorf("expected status %d but got %d", http.StatusOK, code)
+		}
+	case <-refreshed:
+		t.Error("expected readyz to answer while the query is blocked")
+	}
+}
+
+// stallingConn drops the requests it receives once stall is set, signaling stalled.
+type stallingConn struct {
+	net.PacketConn
+	stall   atomic.Bool
+	stalled chan struct{}
+}
+
+func (c *stallingConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	for {
+		n, addr, err := c.PacketConn.ReadFrom(p)
+		if err != nil || !c.stall.Load() {
+			return n, addr, err
+		}
+		select {
+		case c.stalled <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// testServe runs a test A2S server and returns its address.
+func testServe(t *testing.T, srv *testserver.TestServer) string {
+	t.Helper()
+
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	go func() {
+		_ = srv.Serve(conn)
+	}()
+
+	return conn.LocalAddr().String()
+}
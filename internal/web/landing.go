@@ -0,0 +1,118 @@
+package web
+
+import (
+	"html/template"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+)
+
+// LandingPageOpts configures the landing page.
+type LandingPageOpts struct {
+	Version     string
+	MetricsPath string
+	ProbePath   string
+	// StatusPagePath links to the status page, if it is enabled.
+	StatusPagePath string
+	Targets        []*collector.Collector
+}
+
+var landingPageTemplate = template.Must(template.New("landing").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>A2S Exporter</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.8em; text-align: left; }
+.up { color: #2a7; }
+.down { color: #c33; }
+.pending { color: #888; }
+</style>
+</head>
+<body>
+<h1>A2S Exporter</h1>
+<p>Version: {{ .Version }}</p>
+<ul>
+<li><a href="{{ .MetricsPath }}">Metrics</a></li>
+<li><a href="/healthz">Health</a></li>
+<li><a href="/readyz">Readiness</a></li>
+<li><a href="/api/v1/servers">JSON API</a></li>
+{{- if .StatusPagePath }}
+<li><a href="{{ .StatusPagePath }}">Status page</a></li>
+{{- end }}
+</ul>
+<h2>Targets</h2>
+<table>
+<tr><th>Address</th><th>Server name</th><th>Status</th><th>Last query</th><th>Error</th><th></th><th></th></tr>
+{{- range .Targets }}
+<tr>
+<td>{{ .Addr }}</td>
+<td>{{ .ServerName }}</td>
+<td class="{{ .State }}">{{ .State }}</td>
+<td>{{ .LastQuery }}</td>
+<td>{{ .Err }}</td>
+<td><a href="{{ .ProbeURL }}">Probe</a></td>
+<td><a href="{{ .BadgeURL }}">Badge</a></td>
+</tr>
+{{- end }}
+</table>
+</body>
+</html>
+`))
+
+type landingPageTarget struct {
+	Addr       string
+	ServerName string
+	State      string
+	LastQuery  string
+	Err        string
+	ProbeURL   string
+	BadgeURL   string
+}
+
+// LandingPageHandler serves an HTML page linking to the other endpoints and listing the status of each target.
+func LandingPageHandler(opts LandingPageOpts) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+
+		targets := make([]landingPageTarget, 0, len(opts.Targets))
+
+		for _, c := range opts.Targets {
+			status := c.Status()
+			target := landingPageTarget{
+				Addr:       c.Addr(),
+				ServerName: status.ServerName,
+				State:      "pending",
+				ProbeURL:   opts.ProbePath + "?" + url.Values{"target": {c.Addr()}}.Encode(),
+				BadgeURL:   BadgePath + c.Addr() + ".svg",
+			}
+
+			if !status.Time.IsZero() {
+				target.LastQuery = status.Time.Format(time.RFC3339)
+				target.State = "down"
+				if status.ServerUp {
+					target.State = "up"
+				}
+			}
+
+			if status.Err != nil {
+				target.Err = status.Err.Error()
+			}
+
+			targets = append(targets, target)
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = landingPageTemplate.Execute(w, struct {
+			LandingPageOpts
+			Targets []landingPageTarget
+		}{opts, targets})
+	})
+}
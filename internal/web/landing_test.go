@@ -0,0 +1,48 @@
+package web_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rumblefrog/go-a2s"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+	"github.com/armsnyder/a2s-exporter/internal/testserver"
+	"github.com/armsnyder/a2s-exporter/internal/web"
+)
+
+func TestLandingPageHandler(t *testing.T) {
+	addr := testServe(t, &testserver.TestServer{ServerInfo: &a2s.ServerInfo{Name: "foo"}})
+	c := collector.New("", addr, collector.Options{})
+	defer c.Close()
+	c.Refresh()
+
+	handler := web.LandingPageHandler(web.LandingPageOpts{
+		Version:     "v1.2.3",
+		MetricsPath: "/metrics",
+		ProbePath:   "/probe",
+		Targets:     []*collector.Collector{c},
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, rec.Code)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{"v1.2.3", `href="/metrics"`, addr, "foo", `class="up"`, "/probe?target="} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected landing page to contain %q", want)
+		}
+	}
+
+	// Other paths are not found.
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/nope", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status %d but got %d", http.StatusNotFound, rec.Code)
+	}
+}
@@ -0,0 +1,115 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// unixPrefix marks a listen address as a unix socket path rather than a TCP host:port.
+const unixPrefix = "unix:"
+
+// Listen opens a listener for each of the provided addresses. An address is either a TCP host:port or a unix socket
+// path prefixed with "unix:". If systemdSocket is true, the addresses are ignored and the sockets passed to the
+// process by systemd socket activation are used instead.
+func Listen(addrs []string, systemdSocket bool) ([]net.Listener, error) {
+	if systemdSocket {
+		return systemdListeners()
+	}
+
+	if len(addrs) == 0 {
+		return nil, errors.New("no listen address provided")
+	}
+
+	listeners := make([]net.Listener, 0, len(addrs))
+
+	for _, addr := range addrs {
+		listener, err := listen(addr)
+		if err != nil {
+			closeAll(listeners)
+			return nil, err
+		}
+		listeners = append(listeners, listener)
+	}
+
+	return listeners, nil
+}
+
+func listen(addr string) (net.Listener, error) {
+	if !strings.HasPrefix(addr, unixPrefix) {
+		return net.Listen("tcp", addr)
+	}
+
+	path := strings.TrimPrefix(addr, unixPrefix)
+	if path == "" {
+		return nil, fmt.Errorf("listen address %q is missing a socket path", addr)
+	}
+
+	// A socket file left behind by a previous run would cause the listen to fail, so remove it. A socket which another
+	// process still serves, and anything other than a socket, is left alone.
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		conn, err := net.Dial("unix", path)
+		if err == nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("socket %s is in use by another process", path)
+		}
+		if !errors.Is(err, syscall.ECONNREFUSED) {
+			return nil, fmt.Errorf("could not check socket %s: %w", path, err)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("could not remove stale socket %s: %w", path, err)
+		}
+	}
+
+	return net.Listen("unix", path)
+}
+
+// systemdListeners returns the listeners passed by systemd socket activation.
+// See: https://www.freedesktop.org/software/systemd/man/sd_listen_fds.html
+func systemdListeners() ([]net.Listener, error) {
+	// File descriptors passed by systemd start at 3, after stdin, stdout and stderr.
+	const firstFD = 3
+
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, errors.New("no sockets were passed to this process by systemd")
+	}
+
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count <= 0 {
+		return nil, errors.New("no sockets were passed to this process by systemd")
+	}
+
+	// Unset the variables so that they are not inherited by any child process.
+	for _, key := range []string{"LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES"} {
+		_ = os.Unsetenv(key)
+	}
+
+	listeners := make([]net.Listener, 0, count)
+
+	for fd := firstFD; fd < firstFD+count; fd++ {
+		file := os.NewFile(uintptr(fd), fmt.Sprintf("systemd-socket-%d", fd))
+
+		// FileListener duplicates the file descriptor, so the original can be closed.
+		listener, err := net.FileListener(file)
+		_ = file.Close()
+		if err != nil {
+			closeAll(listeners)
+			return nil, fmt.Errorf("could not use systemd socket %d: %w", fd, err)
+		}
+
+		listeners = append(listeners, listener)
+	}
+
+	return listeners, nil
+}
+
+func closeAll(listeners []net.Listener) {
+	for _, listener := range listeners {
+		_ = listener.Close()
+	}
+}
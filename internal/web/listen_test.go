@@ -0,0 +1,122 @@
+package web_test
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/armsnyder/a2s-exporter/internal/web"
+)
+
+func TestListen(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "a2s-exporter.sock")
+
+	listeners, err := web.Listen([]string{"127.0.0.1:0", "unix:" + socketPath}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listeners) != 2 {
+		t.Fatalf("expected 2 listeners but got %d", len(listeners))
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+
+	for _, listener := range listeners {
+		go func(listener net.Listener) {
+			_ = http.Serve(listener, handler)
+		}(listener)
+	}
+
+	// TCP listener.
+	resp, err := http.Get("http://" + listeners[0].Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	// Unix socket listener.
+	client := &http.Client{Transport: &http.Transport{
+		Dial: func(_, _ string) (net.Conn, error) {
+			return net.Dial("unix", socketPath)
+		},
+	}}
+	resp, err = client.Get("http://unix/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	for _, listener := range listeners {
+		_ = listener.Close()
+	}
+}
+
+func TestListen_StaleSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "a2s-exporter.sock")
+
+	// Leave a socket file behind, as happens when the process is killed.
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: socketPath, Net: "unix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.SetUnlinkOnClose(false)
+	_ = stale.Close()
+
+	listeners, err := web.Listen([]string{"unix:" + socketPath}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = listeners[0].Close()
+}
+
+func TestListen_SocketInUse(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "a2s-exporter.sock")
+
+	listeners, err := web.Listen([]string{"unix:" + socketPath}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listeners[0].Close()
+	go func() {
+		for {
+			conn, err := listeners[0].Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	// A second instance must not take over the socket of a running one.
+	if _, err := web.Listen([]string{"unix:" + socketPath}, false); err == nil {
+		t.Error("expected an error for a socket in use")
+	}
+	if _, err := os.Stat(socketPath); err != nil {
+		t.Errorf("expected the socket to be kept, got %v", err)
+	}
+}
+
+func TestListen_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		addrs         []string
+		systemdSocket bool
+	}{
+		{name: "no addresses"},
+		{name: "missing socket path", addrs: []string{"unix:"}},
+		{name: "bad address", addrs: []string{"not an address"}},
+		{name: "no systemd sockets", systemdSocket: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := web.Listen(tt.addrs, tt.systemdSocket); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
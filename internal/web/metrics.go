@@ -0,0 +1,79 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+)
+
+// Values of the collect[] query parameter.
+const (
+	collectInfo    = "info"
+	collectPlayers = "players"
+	collectRules   = "rules"
+)
+
+// MetricsHandler serves the metrics of the targets, followed by those of the gatherer if it is not nil. A scrape may
+// select what is collected from the targets with query parameters, see scopeFromRequest.
+func MetricsHandler(targets []*collector.Collector, gatherer prometheus.Gatherer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		registry := prometheus.NewRegistry()
+
+		for _, target := range targets {
+			scope, err := scopeFromRequest(r, target.DefaultScope())
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err := registry.Register(target.Scoped(scope)); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		gatherers := prometheus.Gatherers{registry}
+		if gatherer != nil {
+			gatherers = append(gatherers, gatherer)
+		}
+
+		promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+	})
+}
+
+// scopeFromRequest applies the query parameters of a scrape to the default scope of a target:
+//
+//   - collect[] selects what is collected, and may be repeated: info (always collected), players, and rules.
+//   - players=false or players=true turns the player list off or on.
+func scopeFromRequest(r *http.Request, scope collector.Scope) (collector.Scope, error) {
+	query := r.URL.Query()
+
+	if collect, ok := query["collect[]"]; ok {
+		scope = collector.Scope{}
+		for _, v := range collect {
+			switch v {
+			case collectInfo:
+			case collectPlayers:
+				scope.Players = true
+			case collectRules:
+				scope.Rules = true
+			default:
+				return scope, fmt.Errorf("unknown collect[] value %q", v)
+			}
+		}
+	}
+
+	if v := query.Get("players"); v != "" {
+		players, err := strconv.ParseBool(v)
+		if err != nil {
+			return scope, fmt.Errorf("invalid players value %q", v)
+		}
+		scope.Players = players
+	}
+
+	return scope, nil
+}
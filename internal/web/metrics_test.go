@@ -0,0 +1,95 @@
+package web_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rumblefrog/go-a2s"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+	"github.com/armsnyder/a2s-exporter/internal/testserver"
+	"github.com/armsnyder/a2s-exporter/internal/web"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	addr := testServe(t, &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo", Players: 1},
+		PlayerInfo: &a2s.PlayerInfo{Count: 1, Players: []*a2s.Player{{Name: "jon", Duration: 30}}},
+		Rules:      &a2s.RulesInfo{Count: 2, Rules: map[string]string{"mp_timelimit": "30", "sv_cheats": "0"}},
+	})
+	target := collector.New("a2s", addr, collector.Options{})
+
+	extra := prometheus.NewRegistry()
+	extra.MustRegister(prometheus.NewGauge(prometheus.GaugeOpts{Name: "extra"}))
+
+	handler := web.MetricsHandler([]*collector.Collector{target}, extra)
+
+	tests := []struct {
+		name    string
+		query   string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "default",
+			want:    []string{"a2s_server_players", "a2s_player_count", "extra"},
+			notWant: []string{"a2s_server_rules"},
+		},
+		{
+			name:    "info only",
+			query:   "collect[]=info",
+			want:    []string{"a2s_server_players"},
+			notWant: []string{"a2s_player_up", "a2s_player_count", "a2s_server_rules"},
+		},
+		{
+			name:    "info and rules",
+			query:   "collect[]=info&collect[]=rules",
+			want:    []string{"a2s_server_players", `a2s_server_rules{server_name="foo"} 2`, "a2s_rules_up 1"},
+			notWant: []string{"a2s_player_count"},
+		},
+		{
+			name:    "players off",
+			query:   "players=false",
+			want:    []string{"a2s_server_players"},
+			notWant: []string{"a2s_player_count"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics?"+tt.query, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status %d but got %d", http.StatusOK, rec.Code)
+			}
+
+			body := rec.Body.String()
+			for _, want := range tt.want {
+				if !strings.Contains(body, want) {
+					t.Errorf("expected metrics to contain %q but got:\n%s", want, body)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(body, notWant) {
+					t.Errorf("expected metrics not to contain %q but got:\n%s", notWant, body)
+				}
+			}
+		})
+	}
+
+	// An info-only scrape keeps the player list of the previous scrape in the status.
+	if status := target.Status(); status.PlayerInfo == nil {
+		t.Error("expected the player list to be kept in the status")
+	}
+
+	for _, query := range []string{"collect[]=nope", "players=maybe"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics?"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d but got %d", query, http.StatusBadRequest, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+)
+
+// ProbeHandler serves the metrics of the A2S server given by the "target" query parameter, in the style of the
+// blackbox exporter. A new Collector is created for each request, with the settings of the module given by the
+// optional "module" query parameter. What is collected may be selected with the same query parameters as the
+// MetricsHandler.
+func ProbeHandler(newCollector func(addr, module string) (*collector.Collector, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target := r.URL.Query().Get("target")
+		if target == "" {
+			http.Error(w, "target parameter is required", http.StatusBadRequest)
+			return
+		}
+
+		c, err := newCollector(target, r.URL.Query().Get("module"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer c.Close()
+
+		scope, err := scopeFromRequest(r, c.DefaultScope())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(c.Scoped(scope))
+
+		promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+	})
+}
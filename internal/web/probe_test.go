@@ -0,0 +1,67 @@
+package web_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/rumblefrog/go-a2s"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+	"github.com/armsnyder/a2s-exporter/internal/testserver"
+	"github.com/armsnyder/a2s-exporter/internal/web"
+)
+
+func TestProbeHandler(t *testing.T) {
+	addr := testServe(t, &testserver.TestServer{ServerInfo: &a2s.ServerInfo{Name: "foo", Players: 3}})
+
+	handler := web.ProbeHandler(func(addr, module string) (*collector.Collector, error) {
+		switch module {
+		case "":
+			return collector.New("a2s", addr, collector.Options{ExcludePlayerMetrics: true}), nil
+		case "players":
+			return collector.New("a2s", addr, collector.Options{}), nil
+		default:
+			return nil, fmt.Errorf("unknown module %s", module)
+		}
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/probe?"+url.Values{"target": {addr}}.Encode(), nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, rec.Code)
+	}
+	if want := `a2s_server_players{server_name="foo"} 3`; !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("expected probe to contain %q but got:\n%s", want, rec.Body)
+	}
+
+	// What is collected may be selected.
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/probe?"+url.Values{"target": {addr}, "players": {"true"}}.Encode(), nil))
+	if want := "a2s_player_up 1"; !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("expected probe to contain %q but got:\n%s", want, rec.Body)
+	}
+
+	// The module selects the settings.
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/probe?"+url.Values{"target": {addr}, "module": {"players"}}.Encode(), nil))
+	if want := "a2s_player_up 1"; !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("expected probe to contain %q but got:\n%s", want, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/probe?"+url.Values{"target": {addr}, "module": {"nope"}}.Encode(), nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for an unknown module but got %d", http.StatusBadRequest, rec.Code)
+	}
+
+	// The target parameter is required.
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/probe", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d but got %d", http.StatusBadRequest, rec.Code)
+	}
+}
@@ -0,0 +1,132 @@
+package web
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+)
+
+//go:embed templates/status.html
+var defaultTemplates embed.FS
+
+// statusPageTemplateName is the name of the template executed by the status page. A template directory must contain a
+// file with this name, and may contain other *.html files which it references.
+const statusPageTemplateName = "status.html"
+
+// StatusPageOpts configures the status page.
+type StatusPageOpts struct {
+	Targets []*collector.Collector
+	// TemplateDir is a directory of templates which override the built-in status page. Optional.
+	TemplateDir string
+}
+
+// StatusPageData is the data passed to the status page template.
+type StatusPageData struct {
+	Servers []StatusPageServer
+	// Time is when the page was rendered.
+	Time time.Time
+}
+
+// StatusPageServer is a target in StatusPageData.
+type StatusPageServer struct {
+	Target     string
+	Up         bool
+	LastQuery  time.Time
+	Name       string
+	Map        string
+	Players    int
+	MaxPlayers int
+	// PlayerList is sorted by descending duration. It is empty if the player query failed.
+	PlayerList []StatusPagePlayer
+}
+
+// StatusPagePlayer is a player in StatusPageServer.
+type StatusPagePlayer struct {
+	Name     string
+	Score    int
+	Duration time.Duration
+}
+
+// StatusPageHandler serves a human-readable HTML status page of each target.
+func StatusPageHandler(opts StatusPageOpts) (http.Handler, error) {
+	tmpl := template.New("").Funcs(template.FuncMap{"duration": formatDuration})
+
+	var err error
+	if opts.TemplateDir == "" {
+		tmpl, err = tmpl.ParseFS(defaultTemplates, "templates/*.html")
+	} else {
+		tmpl, err = tmpl.ParseGlob(filepath.Join(opts.TemplateDir, "*.html"))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not parse status page templates: %w", err)
+	}
+
+	if tmpl.Lookup(statusPageTemplateName) == nil {
+		return nil, fmt.Errorf("template directory %s does not contain %s", opts.TemplateDir, statusPageTemplateName)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		data := StatusPageData{
+			Servers: make([]StatusPageServer, 0, len(opts.Targets)),
+			Time:    time.Now(),
+		}
+
+		for _, target := range opts.Targets {
+			data.Servers = append(data.Servers, newStatusPageServer(target))
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := tmpl.ExecuteTemplate(w, statusPageTemplateName, data); err != nil {
+			fmt.Fprintln(os.Stderr, "Could not render status page:", err)
+		}
+	}), nil
+}
+
+func newStatusPageServer(target *collector.Collector) StatusPageServer {
+	status := target.Status()
+
+	server := StatusPageServer{
+		Target:    target.Addr(),
+		Up:        status.ServerUp,
+		LastQuery: status.Time,
+	}
+
+	if status.ServerInfo != nil {
+		server.Name = status.ServerInfo.Name
+		server.Map = status.ServerInfo.Map
+		server.Players = int(status.ServerInfo.Players)
+		server.MaxPlayers = int(status.ServerInfo.MaxPlayers)
+	}
+
+	if status.PlayerInfo != nil {
+		for _, player := range status.PlayerInfo.Players {
+			server.PlayerList = append(server.PlayerList, StatusPagePlayer{
+				Name:     player.Name,
+				Score:    int(player.Score),
+				Duration: time.Duration(player.Duration) * time.Second,
+			})
+		}
+
+		sort.SliceStable(server.PlayerList, func(i, j int) bool {
+			return server.PlayerList[i].Duration > server.PlayerList[j].Duration
+		})
+	}
+
+	return server
+}
+
+// formatDuration formats a duration as hours and minutes, for example "1h05m".
+func formatDuration(d time.Duration) string {
+	d = d.Truncate(time.Minute)
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+	return fmt.Sprintf("%dh%02dm", d/time.Hour, (d%time.Hour)/time.Minute)
+}
@@ -0,0 +1,86 @@
+package web_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rumblefrog/go-a2s"
+
+	"github.com/armsnyder/a2s-exporter/internal/collector"
+	"github.com/armsnyder/a2s-exporter/internal/testserver"
+	"github.com/armsnyder/a2s-exporter/internal/web"
+)
+
+func TestStatusPageHandler(t *testing.T) {
+	addr := testServe(t, &testserver.TestServer{
+		ServerInfo: &a2s.ServerInfo{Name: "foo", Map: "de_dust2", Players: 2, MaxPlayers: 8},
+		PlayerInfo: &a2s.PlayerInfo{Count: 2, Players: []*a2s.Player{
+			{Name: "jon", Duration: 60},
+			{Name: "alice", Duration: 3900},
+		}},
+	})
+	c := collector.New("", addr, collector.Options{})
+	defer c.Close()
+	c.Refresh()
+
+	handler, err := web.StatusPageHandler(web.StatusPageOpts{Targets: []*collector.Collector{c}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, rec.Code)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{"foo", "de_dust2", "2/8", "jon", "1m", "alice", "1h05m", `class="server up"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected status page to contain %q", want)
+		}
+	}
+
+	// Players are sorted by descending duration.
+	if strings.Index(body, "alice") > strings.Index(body, "jon") {
+		t.Error("expected alice to be listed before jon")
+	}
+}
+
+func TestStatusPageHandler_TemplateDir(t *testing.T) {
+	dir := t.TempDir()
+	tmpl := `{{ define "status.html" }}{{ range .Servers }}{{ template "branding" }} {{ .Target }}{{ end }}{{ end }}`
+	if err := os.WriteFile(filepath.Join(dir, "status.html"), []byte(tmpl), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "branding.html"), []byte(`{{ define "branding" }}My Community{{ end }}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := collector.New("", "127.0.0.1:1", collector.Options{})
+	handler, err := web.StatusPageHandler(web.StatusPageOpts{Targets: []*collector.Collector{c}, TemplateDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	if got, want := rec.Body.String(), "My Community 127.0.0.1:1"; got != want {
+		t.Errorf("expected %q but got %q", want, got)
+	}
+}
+
+func TestStatusPageHandler_MissingTemplate(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "other.html"), []byte("hi"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := web.StatusPageHandler(web.StatusPageOpts{TemplateDir: dir}); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -3,16 +3,26 @@ package main
 import (
 	"flag"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"regexp"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rumblefrog/go-a2s"
 
 	"github.com/armsnyder/a2s-exporter/internal/collector"
+	"github.com/armsnyder/a2s-exporter/internal/config"
+	"github.com/armsnyder/a2s-exporter/internal/events"
+	"github.com/armsnyder/a2s-exporter/internal/notifier"
+	"github.com/armsnyder/a2s-exporter/internal/state"
+	"github.com/armsnyder/a2s-exporter/internal/web"
 )
 
 // buildVersion variable is set at build time.
@@ -21,11 +31,50 @@ var buildVersion = "development"
 func main() {
 	// Flags.
 	address := flag.String("address", envOrDefault("A2S_EXPORTER_QUERY_ADDRESS", ""), "Address of the A2S query server as host:port (This is a separate port from the main server port).")
-	port := flag.Int("port", envOrDefaultInt("A2S_EXPORTER_PORT", 9841), "Port for the metrics exporter.")
+	port := flag.Int("port", envOrDefaultInt("A2S_EXPORTER_PORT", 9841), "Port for the metrics exporter. Ignored if --web.listen-address is set.")
+	listenAddresses := &stringsFlag{values: envOrDefaultStrings("A2S_EXPORTER_WEB_LISTEN_ADDRESS", nil)}
+	flag.Var(listenAddresses, "web.listen-address", "Address to listen on as host:port, or unix:/path for a unix socket. May be repeated. (default \":<port>\")")
+	systemdSocket := flag.Bool("web.systemd-socket", envOrDefaultBool("A2S_EXPORTER_WEB_SYSTEMD_SOCKET", false), "If true, listen on the sockets passed by systemd socket activation instead of --web.listen-address.")
 	path := flag.String("path", envOrDefault("A2S_EXPORTER_PATH", "/metrics"), "Path for the metrics exporter.")
 	namespace := flag.String("namespace", envOrDefault("A2S_EXPORTER_NAMESPACE", "a2s"), "Namespace prefix for all exported a2s metrics.")
 	excludePlayerMetrics := flag.Bool("exclude-player-metrics", envOrDefaultBool("A2S_EXPORTER_EXCLUDE_PLAYER_METRICS", false), "If true, exclude all `player_*` metrics. This option may be necessary for some servers.")
+	playerMode := flag.String("player-mode", envOrDefault("A2S_EXPORTER_PLAYER_MODE", string(collector.PlayerModeLabeled)), "How player metrics are exported: labeled (a series per player), aggregated (distributions across all players, without player labels), or both.")
+	identityLabels := &stringsFlag{values: envOrDefaultStrings("A2S_EXPORTER_IDENTITY_LABEL", []string{string(collector.IdentityName)})}
+	flag.Var(identityLabels, "identity-label", "Label which identifies the server on every metric: name (server_name, which changes when the server is renamed), address (server_address, the query address), alias (server_alias, set with --alias), or steam_id (server_steam_id). May be repeated. Without name, server_name is only exported on server_info and server_name_info.")
+	alias := flag.String("alias", envOrDefault("A2S_EXPORTER_ALIAS", ""), "Alias of the server, for the alias identity label.")
+	labels := &stringsFlag{values: envOrDefaultStrings("A2S_EXPORTER_LABEL", nil)}
+	flag.Var(labels, "label", "Label added to every metric as key=value, for example env=prod. May be repeated. Labels of a target in the configuration file take precedence.")
+	infoMode := flag.String("info-mode", envOrDefault("A2S_EXPORTER_INFO_MODE", string(collector.InfoModeCombined)), "How the server info is exported: combined (server_info with all the info in its labels), split (server_version_info, server_map_info, server_game_info and server_keywords_info, each with few labels), or both.")
+	maxPlayerSeries := flag.Int("max-player-series", envOrDefaultInt("A2S_EXPORTER_MAX_PLAYER_SERIES", 0), "Maximum number of players exported with per-player labels. 0 means no limit.")
+	playerOrder := flag.String("player-order", envOrDefault("A2S_EXPORTER_PLAYER_ORDER", string(collector.PlayerOrderScore)), "Which players are kept when --max-player-series is exceeded: score (highest score) or duration (longest connected).")
+	playerNameMode := flag.String("player-name-mode", envOrDefault("A2S_EXPORTER_PLAYER_NAME_MODE", string(collector.PlayerNameRaw)), "How player names appear in metrics, events and the web pages: raw, hashed (keyed with --player-name-secret), or omitted.")
+	playerNameSecret := flag.String("player-name-secret", envOrDefault("A2S_EXPORTER_PLAYER_NAME_SECRET", ""), "Secret key used to hash player names when --player-name-mode is hashed. Prefer setting the variable, so that the secret does not appear in the process list.")
+	duplicatePlayerMode := flag.String("duplicate-player-mode", envOrDefault("A2S_EXPORTER_DUPLICATE_PLAYER_MODE", string(collector.DuplicateDrop)), "How players with the same name are exported: drop (keep the first), suffix (append #2, #3, ... to duplicates, which players keep while connected), or aggregate (sum scores, take the longest duration, and count them).")
+	normalizePlayerNames := flag.Bool("player-name-normalize", envOrDefaultBool("A2S_EXPORTER_PLAYER_NAME_NORMALIZE", false), "If true, strip rich text tags, color codes and control characters from player names, and trim whitespace. Invalid UTF-8 is always replaced.")
+	playerNameMaxLength := flag.Int("player-name-max-length", envOrDefaultInt("A2S_EXPORTER_PLAYER_NAME_MAX_LENGTH", 0), "Truncate normalized player names to this many characters. 0 means no limit.")
+	excludePlayerNames := &stringsFlag{values: envOrDefaultStrings("A2S_EXPORTER_PLAYER_NAME_EXCLUDE", nil)}
+	flag.Var(excludePlayerNames, "player-name-exclude", "Regular expression of player names to leave out of all outputs, for example bots. Matched against the normalized name. Excluded players are still counted by player_list_entries. May be repeated.")
+	botNames := &stringsFlag{values: envOrDefaultStrings("A2S_EXPORTER_BOT_NAME_PATTERN", []string{`(?i)^\[?bot\]?\s`})}
+	flag.Var(botNames, "bot-name-pattern", "Regular expression of player names which are classified as bots in the player_list_entries metric. May be repeated.")
+	includeMetrics := &stringsFlag{values: envOrDefaultStrings("A2S_EXPORTER_METRIC_INCLUDE", nil)}
+	flag.Var(includeMetrics, "metric-include", "Regular expression of metric names (without the namespace) to export. All metrics are exported if not set. May be repeated.")
+	excludeMetrics := &stringsFlag{values: envOrDefaultStrings("A2S_EXPORTER_METRIC_EXCLUDE", nil)}
+	flag.Var(excludeMetrics, "metric-exclude", "Regular expression of metric names (without the namespace) to leave out. May be repeated.")
+	dropLabels := &stringsFlag{values: envOrDefaultStrings("A2S_EXPORTER_METRIC_DROP_LABEL", nil)}
+	flag.Var(dropLabels, "metric-drop-label", "Label to leave out of a metric as metric=label, for example server_info=keywords. May be repeated.")
 	a2sOnlyMetrics := flag.Bool("a2s-only-metrics", envOrDefaultBool("A2S_EXPORTER_A2S_ONLY_METRICS", false), "If true, excludes Go runtime and promhttp metrics.")
+	queryRules := flag.Bool("query-rules", envOrDefaultBool("A2S_EXPORTER_QUERY_RULES", false), "If true, also query the server rules, which are served by the JSON API and counted by the server_rules metric.")
+	corsOrigins := &stringsFlag{values: envOrDefaultStrings("A2S_EXPORTER_WEB_CORS_ORIGIN", nil)}
+	flag.Var(corsOrigins, "web.cors-origin", "Origin allowed to make cross-origin requests to the JSON API, or * for any origin. May be repeated.")
+	statusPage := flag.Bool("web.status-page", envOrDefaultBool("A2S_EXPORTER_WEB_STATUS_PAGE", false), "If true, serve a public HTML server status page at /status.")
+	statusTemplateDir := flag.String("web.status-template-dir", envOrDefault("A2S_EXPORTER_WEB_STATUS_TEMPLATE_DIR", ""), "Directory of templates overriding the built-in status page. It must contain status.html.")
+	eventsFile := flag.String("events.file", envOrDefault("A2S_EXPORTER_EVENTS_FILE", ""), "If set, append player join/leave, map change and server restart events to this file as JSON lines.")
+	eventsFileMaxSize := flag.Int("events.file-max-size", envOrDefaultInt("A2S_EXPORTER_EVENTS_FILE_MAX_SIZE", 100), "Size in megabytes at which the events file is rotated. 0 disables rotation.")
+	eventsFileMaxBackups := flag.Int("events.file-max-backups", envOrDefaultInt("A2S_EXPORTER_EVENTS_FILE_MAX_BACKUPS", 5), "Number of rotated events files to keep.")
+	eventsStdout := flag.Bool("events.stdout", envOrDefaultBool("A2S_EXPORTER_EVENTS_STDOUT", false), "If true, print events to stdout as JSON lines. Logs are always written to stderr.")
+	stateFile := flag.String("state.file", envOrDefault("A2S_EXPORTER_STATE_FILE", ""), "If set, persist player sessions and rolling statistics to this file, so that they survive a restart of the exporter.")
+	stateInterval := flag.Duration("state.interval", envOrDefaultDuration("A2S_EXPORTER_STATE_INTERVAL", time.Minute), "How often the state is saved to --state.file. It is also saved on shutdown. 0 disables the periodic save.")
+	configFile := flag.String("config.file", envOrDefault("A2S_EXPORTER_CONFIG_FILE", ""), "Path to an optional YAML configuration file, for options which do not fit in a flag such as notifiers.")
 	maxPacketSize := flag.Int("max-packet-size", envOrDefaultInt("A2S_EXPORTER_MAX_PACKET_SIZE", 1400), "Advanced option to set a non-standard max packet size of the A2S query server.")
 	help := flag.Bool("h", false, "Show help.")
 	version := flag.Bool("version", false, "Show build version.")
@@ -46,38 +95,362 @@ func main() {
 
 	// Check required arguments.
 	if *address == "" {
-		fmt.Println("address argument is required")
+		fmt.Fprintln(os.Stderr, "address argument is required")
 		flag.Usage()
 		os.Exit(1)
 	}
 
-	// Set up prometheus metrics registry.
-	var registry *prometheus.Registry
-	if *a2sOnlyMetrics {
-		registry = prometheus.NewRegistry()
-	} else {
-		registry = prometheus.DefaultRegisterer.(*prometheus.Registry)
+	parsedPlayerMode, err := collector.ParsePlayerMode(*playerMode)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	parsedIdentityLabels, err := collector.ParseIdentityLabels(identityLabels.values)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	parsedLabels, err := collector.ParseLabels(labels.values)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid label argument:", err)
+		os.Exit(1)
+	}
+
+	parsedInfoMode, err := collector.ParseInfoMode(*infoMode)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	parsedPlayerOrder, err := collector.ParsePlayerOrder(*playerOrder)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	parsedPlayerNameMode, err := collector.ParsePlayerNameMode(*playerNameMode)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	if parsedPlayerNameMode == collector.PlayerNameHashed && *playerNameSecret == "" {
+		fmt.Fprintln(os.Stderr, "player-name-secret argument is required when player-name-mode is hashed")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	parsedDuplicatePlayerMode, err := collector.ParseDuplicateMode(*duplicatePlayerMode)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	excludePlayerNamePatterns, err := compilePatterns(excludePlayerNames.values)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid player-name-exclude argument:", err)
+		os.Exit(1)
+	}
+
+	botNamePatterns, err := compilePatterns(botNames.values)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid bot-name-pattern argument:", err)
+		os.Exit(1)
+	}
+
+	includeMetricPatterns, err := compileFullPatterns(includeMetrics.values)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid metric-include argument:", err)
+		os.Exit(1)
+	}
+
+	excludeMetricPatterns, err := compileFullPatterns(excludeMetrics.values)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid metric-exclude argument:", err)
+		os.Exit(1)
+	}
+
+	parsedDropLabels, err := collector.ParseDropLabels(dropLabels.values)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid metric-drop-label argument:", err)
+		os.Exit(1)
+	}
+
+	// Load the configuration file.
+	cfg := &config.Config{}
+	if *configFile != "" {
+		if cfg, err = config.Load(*configFile); err != nil {
+			fmt.Fprintln(os.Stderr, "Could not load config file:", err)
+			os.Exit(1)
+		}
 	}
 
 	// Register A2S metrics.
 	clientOptions := []func(*a2s.Client) error{
 		a2s.SetMaxPacketSize(uint32(*maxPacketSize)),
 	}
-	registry.MustRegister(collector.New(*namespace, *address, *excludePlayerMetrics, clientOptions...))
+	collectorOptions := collector.Options{
+		ExcludePlayerMetrics: *excludePlayerMetrics,
+		PlayerMode:           parsedPlayerMode,
+		IdentityLabels:       parsedIdentityLabels,
+		InfoMode:             parsedInfoMode,
+		MaxPlayerSeries:      *maxPlayerSeries,
+		PlayerOrder:          parsedPlayerOrder,
+		PlayerNameMode:       parsedPlayerNameMode,
+		PlayerNameSecret:     []byte(*playerNameSecret),
+		DuplicateMode:        parsedDuplicatePlayerMode,
+		NormalizePlayerNames: *normalizePlayerNames,
+		PlayerNameMaxLength:  *playerNameMaxLength,
+		ExcludePlayerNames:   excludePlayerNamePatterns,
+		BotNamePatterns:      botNamePatterns,
+		QueryRules:           *queryRules,
+		Labels:               parsedLabels,
+		IncludeMetrics:       includeMetricPatterns,
+		ExcludeMetrics:       excludeMetricPatterns,
+		DropLabels:           parsedDropLabels,
+		ClientOptions:        clientOptions,
+	}
+	// optionsFor applies the settings of a target and of a module from the configuration file. The module defaults to
+	// that of the target.
+	optionsFor := func(addr, module string) (collector.Options, error) {
+		opts, err := cfg.Options(addr, module, collectorOptions)
+		if err != nil {
+			return opts, err
+		}
+		if target := cfg.Target(addr); target != nil {
+			opts.Alias = target.Alias
+			opts.Labels = make(map[string]string, len(parsedLabels)+len(target.Labels))
+			for name, value := range parsedLabels {
+				opts.Labels[name] = value
+			}
+			for name, value := range target.Labels {
+				opts.Labels[name] = value
+			}
+		}
+		return opts, nil
+	}
+	newCollector := func(addr, module string) (*collector.Collector, error) {
+		opts, err := optionsFor(addr, module)
+		if err != nil {
+			return nil, err
+		}
+		// A probe queries the target once, so there is nothing to compare with.
+		opts.Stateless = true
+		return collector.New(*namespace, addr, opts), nil
+	}
+
+	// Set up the event log and notifiers. Events are only tracked for the configured target, not for probes.
+	var eventSinks events.Multi
+	if *eventsFile != "" {
+		file, err := events.OpenRotatingFile(*eventsFile, int64(*eventsFileMaxSize)*1024*1024, *eventsFileMaxBackups)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Could not open events file:", err)
+			os.Exit(1)
+		}
+		eventSinks = append(eventSinks, events.NewJSONWriter(file))
+	}
+	if *eventsStdout {
+		eventSinks = append(eventSinks, events.NewJSONWriter(os.Stdout))
+	}
+
+	for _, notifierConfig := range cfg.Notifiers {
+		n, err := notifier.New(notifierConfig)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Could not set up notifier:", err)
+			os.Exit(1)
+		}
+		eventSinks = append(eventSinks, n)
+	}
 
-	// Set up http handler.
-	handler := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
-	if !*a2sOnlyMetrics {
-		handler = promhttp.InstrumentMetricHandler(registry, handler)
+	targetOptions, err := optionsFor(*address, "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *alias != "" {
+		targetOptions.Alias = *alias
+	}
+	if len(eventSinks) > 0 {
+		targetOptions.Events = eventSinks
+	}
+	target := collector.New(*namespace, *address, targetOptions)
+	targets := []*collector.Collector{target}
+
+	// Restore the state saved by the previous run, and keep saving it.
+	var store *state.Store
+	if *stateFile != "" {
+		store = state.New(*stateFile)
+		if err := store.Restore(stateTargets(targets)); err != nil {
+			fmt.Fprintln(os.Stderr, "Could not restore state:", err)
+		}
+
+		go func() {
+			for range time.Tick(*stateInterval) {
+				if err := store.Save(stateTargets(targets)); err != nil {
+					fmt.Fprintln(os.Stderr, "Could not save state:", err)
+				}
+			}
+		}()
+	}
+
+	// Query the target once at startup, so that readiness does not depend on the first scrape.
+	go target.Refresh()
+
+	// Set up http handler. The A2S metrics are collected per scrape, so that a scrape can select what is collected.
+	var handler http.Handler
+	if *a2sOnlyMetrics {
+		handler = web.MetricsHandler(targets, nil)
+	} else {
+		registry := prometheus.DefaultRegisterer.(*prometheus.Registry)
+		handler = promhttp.InstrumentMetricHandler(registry, web.MetricsHandler(targets, registry))
 	}
 
 	http.Handle(*path, handler)
+	http.Handle("/probe", web.ProbeHandler(newCollector))
+	http.Handle("/healthz", web.HealthzHandler())
+	http.Handle("/readyz", web.ReadyzHandler(targets))
+
+	apiHandler := web.APIHandler(web.APIOpts{Targets: targets, CORSOrigins: corsOrigins.values})
+	http.Handle(web.APIPath, apiHandler)
+	http.Handle(web.APIPath+"/", apiHandler)
+
+	http.Handle(web.BadgePath, web.BadgeHandler(targets))
+
+	var statusPagePath string
+	if *statusPage {
+		statusPagePath = "/status"
+		statusHandler, err := web.StatusPageHandler(web.StatusPageOpts{Targets: targets, TemplateDir: *statusTemplateDir})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		http.Handle(statusPagePath, statusHandler)
+	}
+
+	if *path != "/" {
+		http.Handle("/", web.LandingPageHandler(web.LandingPageOpts{
+			Version:        buildVersion,
+			MetricsPath:    *path,
+			ProbePath:      "/probe",
+			StatusPagePath: statusPagePath,
+			Targets:        targets,
+		}))
+	}
+
+	// Open listeners.
+	if len(listenAddresses.values) == 0 {
+		listenAddresses.values = []string{fmt.Sprintf(":%d", *port)}
+	}
+
+	listeners, err := web.Listen(listenAddresses.values, *systemdSocket)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not listen:", err)
+		os.Exit(1)
+	}
+
+	// Run http server on each listener. The first one to fail stops the exporter.
+	errs := make(chan error, len(listeners))
+
+	for _, listener := range listeners {
+		fmt.Fprintf(os.Stderr, "Serving metrics at %s\n", listenerURL(listener, *path))
+
+		go func(listener net.Listener) {
+			errs <- http.Serve(listener, nil)
+		}(listener)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	exitCode := 0
+	select {
+	case err := <-errs:
+		fmt.Fprintln(os.Stderr, err)
+		exitCode = 1
+	case sig := <-signals:
+		fmt.Fprintln(os.Stderr, "Received", sig, "shutting down")
+	}
+
+	if store != nil {
+		if err := store.Save(stateTargets(targets)); err != nil {
+			fmt.Fprintln(os.Stderr, "Could not save state:", err)
+		}
+	}
+
+	os.Exit(exitCode)
+}
+
+func stateTargets(targets []*collector.Collector) []state.Target {
+	stateTargets := make([]state.Target, 0, len(targets))
+	for _, target := range targets {
+		stateTargets = append(stateTargets, target)
+	}
+	return stateTargets
+}
 
-	// Run http server.
-	fmt.Printf("Serving metrics at http://127.0.0.1:%d%s\n", *port, *path)
-	fmt.Println(http.ListenAndServe(fmt.Sprintf(":%d", *port), nil))
+// listenerURL returns a human-readable location of the given path on a listener.
+func listenerURL(listener net.Listener, path string) string {
+	addr := listener.Addr()
 
-	os.Exit(1)
+	switch addr := addr.(type) {
+	case *net.UnixAddr:
+		return fmt.Sprintf("unix:%s (path %s)", addr.Name, path)
+	case *net.TCPAddr:
+		// Show the loopback address rather than an unspecified address, so that the URL can be opened directly.
+		if addr.IP.IsUnspecified() {
+			return fmt.Sprintf("http://127.0.0.1:%d%s", addr.Port, path)
+		}
+	}
+
+	return fmt.Sprintf("http://%s%s", addr, path)
+}
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// compileFullPatterns compiles patterns which must match the whole string, like Prometheus relabeling regexes.
+func compileFullPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	anchored := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		anchored = append(anchored, "^(?:"+pattern+")$")
+	}
+	return compilePatterns(anchored)
+}
+
+// stringsFlag is a flag which may be repeated. Values given on the commandline replace the default values.
+type stringsFlag struct {
+	values []string
+	set    bool
+}
+
+func (f *stringsFlag) String() string {
+	return strings.Join(f.values, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	if !f.set {
+		f.values = nil
+		f.set = true
+	}
+	f.values = append(f.values, value)
+	return nil
 }
 
 func envOrDefault(key, def string) string {
@@ -95,6 +468,21 @@ func envOrDefaultInt(key string, def int) int {
 	return def
 }
 
+func envOrDefaultStrings(key string, def []string) []string {
+	if v, ok := os.LookupEnv(key); ok && v != "" {
+		return strings.Split(v, ",")
+	}
+	return def
+}
+
+func envOrDefaultDuration(key string, def time.Duration) time.Duration {
+	if v, ok := os.LookupEnv(key); ok {
+		v2, _ := time.ParseDuration(v)
+		return v2
+	}
+	return def
+}
+
 func envOrDefaultBool(key string, def bool) bool {
 	if v, ok := os.LookupEnv(key); ok {
 		return !strings.EqualFold(v, "false") && !strings.EqualFold(v, "0")